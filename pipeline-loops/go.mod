@@ -3,24 +3,25 @@ module github.com/tektoncd/experimental/pipeline-loops
 go 1.13
 
 require (
-	github.com/google/go-cmp v0.5.2
+	github.com/google/cel-go v0.7.0
+	github.com/google/go-cmp v0.5.4
+	github.com/google/go-containerregistry v0.2.1
 	github.com/hashicorp/go-multierror v1.1.0
-	github.com/tektoncd/pipeline v0.18.0
-	go.opencensus.io v0.22.4
-	go.uber.org/zap v1.15.0
-	golang.org/x/tools v0.0.0-20200924205911-8a9a89368bd3 // indirect
+	github.com/tektoncd/pipeline v0.20.1
+	go.opencensus.io v0.22.5
+	go.uber.org/zap v1.16.0
 	gomodules.xyz/jsonpatch/v2 v2.1.0
-	k8s.io/api v0.18.8
+	google.golang.org/genproto v0.0.0-20201211151036-40ec1c210f7a
+	k8s.io/api v0.18.12
 	k8s.io/apimachinery v0.19.0
 	k8s.io/client-go v11.0.1-0.20190805182717-6502b5e7b1b5+incompatible
-	knative.dev/pkg v0.0.0-20200922164940-4bf40ad82aab
+	knative.dev/pkg v0.0.0-20210107022335-51c72e24c179
 )
 
-// Knative deps
+// Knative deps (release-0.20)
 replace (
-	contrib.go.opencensus.io/exporter/stackdriver => contrib.go.opencensus.io/exporter/stackdriver v0.12.9-0.20191108183826-59d068f8d8ff
+	contrib.go.opencensus.io/exporter/stackdriver => contrib.go.opencensus.io/exporter/stackdriver v0.13.4
 	github.com/Azure/azure-sdk-for-go => github.com/Azure/azure-sdk-for-go v38.2.0+incompatible
-	github.com/Azure/go-autorest => github.com/Azure/go-autorest v13.4.0+incompatible
 )
 
 // Pin k8s deps to v0.18.8
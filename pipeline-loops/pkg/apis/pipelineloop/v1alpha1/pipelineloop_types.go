@@ -57,9 +57,48 @@ type PipelineLoopSpec struct {
 	// +optional
 	Timeout *metav1.Duration `json:"timeout,omitempty"`
 
-	// Retries represents how many times a task should be retried in case of task failure.
+	// Retries represents how many times the PipelineRun of an iteration is retried in case of failure.
+	// Each retry creates a new PipelineRun for the iteration.
 	// +optional
 	Retries int `json:"retries,omitempty"`
+
+	// Concurrency represents how many PipelineRuns can be running at the same time.
+	// The default is 1, which runs the iterations sequentially.  If it is 0 or negative,
+	// the PipelineRuns for all iterations run at the same time.
+	// +optional
+	Concurrency *int `json:"concurrency,omitempty"`
+
+	// BreakCondition is a CEL expression that is evaluated against the results of each PipelineRun
+	// that completes successfully.  The results are available in the "results" map.  When the
+	// expression evaluates to true, no more iterations are started and the Run succeeds.
+	// +optional
+	BreakCondition string `json:"breakCondition,omitempty"`
+
+	// ResultParams pass the results of the PipelineRun of an iteration to the parameters of the
+	// PipelineRun of the next iteration.  They can only be used when the iterations run sequentially.
+	// +optional
+	ResultParams []PipelineLoopResultParam `json:"resultParams,omitempty"`
+
+	// IsolatedWorkspaces are the names of the workspaces of the Run that are bound with a separate
+	// subPath for each iteration, so that the PipelineRuns of different iterations don't share files.
+	// +optional
+	IsolatedWorkspaces []string `json:"isolatedWorkspaces,omitempty"`
+
+	// PruneSucceeded deletes the PipelineRuns of the iterations that succeed once their results are
+	// recorded in the Run status.  Only a summary of their status is kept in the Run.
+	// +optional
+	PruneSucceeded bool `json:"pruneSucceeded,omitempty"`
+}
+
+// PipelineLoopResultParam maps a PipelineResult of an iteration to a parameter of the next iteration.
+type PipelineLoopResultParam struct {
+	// Result is the name of the PipelineResult of an iteration.
+	Result string `json:"result"`
+	// Param is the name of the parameter of the next iteration that receives the result.
+	Param string `json:"param"`
+	// Seed is the value of the parameter for the first iteration.
+	// +optional
+	Seed string `json:"seed,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -98,6 +137,9 @@ const (
 	// PipelineLoopRunReasonCouldntGetPipelineLoop indicates that the associated PipelineLoop couldn't be retrieved
 	PipelineLoopRunReasonCouldntGetPipelineLoop PipelineLoopRunReason = "CouldntGetPipelineLoop"
 
+	// PipelineLoopRunReasonCouldntGetPipeline indicates that the Pipeline referenced by the PipelineLoop couldn't be retrieved
+	PipelineLoopRunReasonCouldntGetPipeline PipelineLoopRunReason = "CouldntGetPipeline"
+
 	// PipelineLoopRunReasonFailedValidation indicates that the PipelineLoop failed runtime validation
 	PipelineLoopRunReasonFailedValidation PipelineLoopRunReason = "PipelineLoopValidationFailed"
 
@@ -113,15 +155,32 @@ func (t PipelineLoopRunReason) String() string {
 type PipelineLoopRunStatus struct {
 	// PipelineLoopSpec contains the exact spec used to instantiate the Run
 	PipelineLoopSpec *PipelineLoopSpec `json:"pipelineLoopSpec,omitempty"`
+	// PipelineSpec contains the exact spec of the pipeline used by the PipelineRuns.
+	// It is resolved once per Run from the pipelineRef or pipelineSpec of the PipelineLoop.
+	// +optional
+	PipelineSpec *v1beta1.PipelineSpec `json:"pipelineSpec,omitempty"`
 	// map of PipelineLoopPipelineRunStatus with the PipelineRun name as the key
 	// +optional
 	PipelineRuns map[string]*PipelineLoopPipelineRunStatus `json:"pipelineRuns,omitempty"`
+	// CurrentRunning is the number of PipelineRuns that are running
+	// +optional
+	CurrentRunning int `json:"currentRunning,omitempty"`
 }
 
 // PipelineLoopPipelineRunStatus contains the iteration number for a PipelineRun and the PipelineRun's Status
 type PipelineLoopPipelineRunStatus struct {
 	// iteration number
 	Iteration int `json:"iteration,omitempty"`
+	// Attempt is the attempt at the iteration that the PipelineRun runs, starting at 1
+	// +optional
+	Attempt int `json:"attempt,omitempty"`
+	// Retried is true if the PipelineRun failed and was retried by another PipelineRun
+	// +optional
+	Retried bool `json:"retried,omitempty"`
+	// Pruned is true if the PipelineRun succeeded and is deleted once this summary is recorded.  Status then
+	// only keeps its Succeeded condition, results, skipped tasks and start and completion times.
+	// +optional
+	Pruned bool `json:"pruned,omitempty"`
 	// Status is the TaskRunStatus for the corresponding TaskRun
 	// +optional
 	Status *v1beta1.PipelineRunStatus `json:"status,omitempty"`
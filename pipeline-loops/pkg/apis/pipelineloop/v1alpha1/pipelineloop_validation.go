@@ -18,9 +18,14 @@ package v1alpha1
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/tektoncd/pipeline/pkg/apis/validate"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"knative.dev/pkg/apis"
 )
@@ -41,9 +46,72 @@ func (tls *PipelineLoopSpec) Validate(ctx context.Context) *apis.FieldError {
 	if err := validateTask(ctx, tls); err != nil {
 		return err
 	}
+	if tls.Retries < 0 {
+		return apis.ErrInvalidValue(tls.Retries, "spec.retries")
+	}
+	if tls.BreakCondition != "" {
+		if _, err := CompileBreakCondition(tls.BreakCondition); err != nil {
+			return apis.ErrInvalidValue(err.Error(), "spec.breakCondition")
+		}
+	}
+	if err := validateResultParams(tls); err != nil {
+		return err
+	}
+	workspaces := make(map[string]bool, len(tls.IsolatedWorkspaces))
+	for i, name := range tls.IsolatedWorkspaces {
+		if name == "" || workspaces[name] {
+			return apis.ErrInvalidValue(name, fmt.Sprintf("spec.isolatedWorkspaces[%d]", i))
+		}
+		workspaces[name] = true
+	}
 	return nil
 }
 
+func validateResultParams(tls *PipelineLoopSpec) *apis.FieldError {
+	if len(tls.ResultParams) == 0 {
+		return nil
+	}
+	// Results can only be passed from one iteration to the next when the iterations run sequentially.
+	if tls.Concurrency != nil && *tls.Concurrency != 1 {
+		err := apis.ErrInvalidValue(*tls.Concurrency, "spec.concurrency")
+		err.Details = "resultParams can only be used when the iterations run sequentially"
+		return err
+	}
+	params := make(map[string]bool, len(tls.ResultParams))
+	for i, rp := range tls.ResultParams {
+		if rp.Result == "" {
+			return apis.ErrMissingField("result").ViaFieldIndex("spec.resultParams", i)
+		}
+		if rp.Param == "" {
+			return apis.ErrMissingField("param").ViaFieldIndex("spec.resultParams", i)
+		}
+		// The param can't be one that is set by the loop itself or by another result.
+		if rp.Param == tls.IterateParam || rp.Param == tls.IterateNumeric || params[rp.Param] {
+			return apis.ErrInvalidValue(rp.Param, "param").ViaFieldIndex("spec.resultParams", i)
+		}
+		params[rp.Param] = true
+	}
+	return nil
+}
+
+// CompileBreakCondition compiles the CEL expression of a break condition into a program that can be
+// evaluated against the results of a PipelineRun.  The results are declared as the "results" map.
+func CompileBreakCondition(expression string) (cel.Program, error) {
+	env, err := cel.NewEnv(cel.Declarations(
+		decls.NewVar("results", decls.NewMapType(decls.String, decls.String))))
+	if err != nil {
+		return nil, err
+	}
+	ast, iss := env.Compile(expression)
+	if iss.Err() != nil {
+		return nil, iss.Err()
+	}
+	if ast.ResultType().GetPrimitive() != exprpb.Type_BOOL {
+		return nil, fmt.Errorf("expression %q must evaluate to a bool", expression)
+	}
+	return env.Program(ast)
+}
+
 func validateTask(ctx context.Context, tls *PipelineLoopSpec) *apis.FieldError {
 	// pipelineRef and taskSpec are mutually exclusive.
 	if (tls.PipelineRef != nil && tls.PipelineRef.Name != "") && tls.PipelineSpec != nil {
@@ -64,6 +132,12 @@ func validateTask(ctx context.Context, tls *PipelineLoopSpec) *apis.FieldError {
 		if errSlice := validation.IsQualifiedName(tls.PipelineRef.Name); len(errSlice) != 0 {
 			return apis.ErrInvalidValue(strings.Join(errSlice, ","), "spec.pipelineRef.name")
 		}
+		// pipelineRef bundle must be a valid image reference
+		if tls.PipelineRef.Bundle != "" {
+			if _, err := name.ParseReference(tls.PipelineRef.Bundle); err != nil {
+				return apis.ErrInvalidValue(fmt.Sprintf("invalid bundle reference (%s)", err), "spec.pipelineRef.bundle")
+			}
+		}
 	}
 	return nil
 }
@@ -42,6 +42,37 @@ func TestPipelineLoop_Validate_Success(t *testing.T) {
 				PipelineRef: &v1beta1.PipelineRef{Name: "mypipeline"},
 			},
 		},
+	}, {
+		name: "breakCondition",
+		tl: &pipelineloopv1alpha1.PipelineLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "pipelineloop"},
+			Spec: pipelineloopv1alpha1.PipelineLoopSpec{
+				PipelineRef:    &v1beta1.PipelineRef{Name: "mypipeline"},
+				BreakCondition: "has(results.ready) && results.ready == 'true'",
+			},
+		},
+	}, {
+		name: "resultParams",
+		tl: &pipelineloopv1alpha1.PipelineLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "pipelineloop"},
+			Spec: pipelineloopv1alpha1.PipelineLoopSpec{
+				PipelineRef:  &v1beta1.PipelineRef{Name: "mypipeline"},
+				IterateParam: "item",
+				ResultParams: []pipelineloopv1alpha1.PipelineLoopResultParam{{
+					Result: "cursor",
+					Param:  "cursor",
+					Seed:   "0",
+				}},
+			},
+		},
+	}, {
+		name: "pipelineRef to a bundle",
+		tl: &pipelineloopv1alpha1.PipelineLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "pipelineloop"},
+			Spec: pipelineloopv1alpha1.PipelineLoopSpec{
+				PipelineRef: &v1beta1.PipelineRef{Name: "mypipeline", Bundle: "gcr.io/my-project/my-bundle:v1"},
+			},
+		},
 	}, {
 		name: "pipelineSpecWithoutParam",
 		tl: &pipelineloopv1alpha1.PipelineLoop{
@@ -115,6 +146,7 @@ func TestPipelineLoop_Validate_Success(t *testing.T) {
 }
 
 func TestPipelineLoop_Validate_Error(t *testing.T) {
+	two := 2
 	tests := []struct {
 		name          string
 		tl            *pipelineloopv1alpha1.PipelineLoop
@@ -167,6 +199,18 @@ func TestPipelineLoop_Validate_Error(t *testing.T) {
 				"validation is '([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]')",
 			Paths: []string{"spec.pipelineRef.name"},
 		},
+	}, {
+		name: "invalid pipelineRef bundle",
+		tl: &pipelineloopv1alpha1.PipelineLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "pipelineloop"},
+			Spec: pipelineloopv1alpha1.PipelineLoopSpec{
+				PipelineRef: &v1beta1.PipelineRef{Name: "mypipeline", Bundle: "invalid reference"},
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: invalid bundle reference (could not parse reference: invalid reference)",
+			Paths:   []string{"spec.pipelineRef.bundle"},
+		},
 	}, {
 		name: "invalid pipelineSpec",
 		tl: &pipelineloopv1alpha1.PipelineLoop{
@@ -191,6 +235,98 @@ func TestPipelineLoop_Validate_Error(t *testing.T) {
 			Details: "Task step name must be a valid DNS Label, For more info refer to https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names",
 			Paths:   []string{"spec.pipelineSpec.tasks[0].taskSpec.steps[0].name"},
 		},
+	}, {
+		name: "negative retries",
+		tl: &pipelineloopv1alpha1.PipelineLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "pipelineloop"},
+			Spec: pipelineloopv1alpha1.PipelineLoopSpec{
+				PipelineRef: &v1beta1.PipelineRef{Name: "mypipeline"},
+				Retries:     -1,
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: -1",
+			Paths:   []string{"spec.retries"},
+		},
+	}, {
+		name: "break condition that isn't a bool",
+		tl: &pipelineloopv1alpha1.PipelineLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "pipelineloop"},
+			Spec: pipelineloopv1alpha1.PipelineLoopSpec{
+				PipelineRef:    &v1beta1.PipelineRef{Name: "mypipeline"},
+				BreakCondition: "results.ready",
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: `invalid value: expression "results.ready" must evaluate to a bool`,
+			Paths:   []string{"spec.breakCondition"},
+		},
+	}, {
+		name: "resultParams with concurrent iterations",
+		tl: &pipelineloopv1alpha1.PipelineLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "pipelineloop"},
+			Spec: pipelineloopv1alpha1.PipelineLoopSpec{
+				PipelineRef: &v1beta1.PipelineRef{Name: "mypipeline"},
+				Concurrency: &two,
+				ResultParams: []pipelineloopv1alpha1.PipelineLoopResultParam{{
+					Result: "cursor",
+					Param:  "cursor",
+				}},
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: 2",
+			Details: "resultParams can only be used when the iterations run sequentially",
+			Paths:   []string{"spec.concurrency"},
+		},
+	}, {
+		name: "resultParams without result",
+		tl: &pipelineloopv1alpha1.PipelineLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "pipelineloop"},
+			Spec: pipelineloopv1alpha1.PipelineLoopSpec{
+				PipelineRef: &v1beta1.PipelineRef{Name: "mypipeline"},
+				ResultParams: []pipelineloopv1alpha1.PipelineLoopResultParam{{
+					Param: "cursor",
+				}},
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "missing field(s)",
+			Paths:   []string{"spec.resultParams[0].result"},
+		},
+	}, {
+		name: "resultParams that set the iterate param",
+		tl: &pipelineloopv1alpha1.PipelineLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "pipelineloop"},
+			Spec: pipelineloopv1alpha1.PipelineLoopSpec{
+				PipelineRef:  &v1beta1.PipelineRef{Name: "mypipeline"},
+				IterateParam: "item",
+				ResultParams: []pipelineloopv1alpha1.PipelineLoopResultParam{{
+					Result: "cursor",
+					Param:  "cursor",
+				}, {
+					Result: "next",
+					Param:  "item",
+				}},
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: item",
+			Paths:   []string{"spec.resultParams[1].param"},
+		},
+	}, {
+		name: "duplicate isolated workspaces",
+		tl: &pipelineloopv1alpha1.PipelineLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "pipelineloop"},
+			Spec: pipelineloopv1alpha1.PipelineLoopSpec{
+				PipelineRef:        &v1beta1.PipelineRef{Name: "mypipeline"},
+				IsolatedWorkspaces: []string{"scratch", "scratch"},
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: scratch",
+			Paths:   []string{"spec.isolatedWorkspaces[1]"},
+		},
 	}}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
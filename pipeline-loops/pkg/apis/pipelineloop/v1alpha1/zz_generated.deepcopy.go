@@ -107,6 +107,22 @@ func (in *PipelineLoopPipelineRunStatus) DeepCopy() *PipelineLoopPipelineRunStat
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineLoopResultParam) DeepCopyInto(out *PipelineLoopResultParam) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineLoopResultParam.
+func (in *PipelineLoopResultParam) DeepCopy() *PipelineLoopResultParam {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineLoopResultParam)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PipelineLoopRunStatus) DeepCopyInto(out *PipelineLoopRunStatus) {
 	*out = *in
@@ -115,6 +131,11 @@ func (in *PipelineLoopRunStatus) DeepCopyInto(out *PipelineLoopRunStatus) {
 		*out = new(PipelineLoopSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PipelineSpec != nil {
+		in, out := &in.PipelineSpec, &out.PipelineSpec
+		*out = new(v1beta1.PipelineSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.PipelineRuns != nil {
 		in, out := &in.PipelineRuns, &out.PipelineRuns
 		*out = make(map[string]*PipelineLoopPipelineRunStatus, len(*in))
@@ -161,6 +182,21 @@ func (in *PipelineLoopSpec) DeepCopyInto(out *PipelineLoopSpec) {
 		*out = new(v1.Duration)
 		**out = **in
 	}
+	if in.Concurrency != nil {
+		in, out := &in.Concurrency, &out.Concurrency
+		*out = new(int)
+		**out = **in
+	}
+	if in.ResultParams != nil {
+		in, out := &in.ResultParams, &out.ResultParams
+		*out = make([]PipelineLoopResultParam, len(*in))
+		copy(*out, *in)
+	}
+	if in.IsolatedWorkspaces != nil {
+		in, out := &in.IsolatedWorkspaces, &out.IsolatedWorkspaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
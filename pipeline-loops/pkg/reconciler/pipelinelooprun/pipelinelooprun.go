@@ -20,10 +20,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"path"
 	"reflect"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/google/cel-go/cel"
+	"github.com/google/go-containerregistry/pkg/authn/k8schain"
 	"github.com/hashicorp/go-multierror"
 
 	"github.com/tektoncd/experimental/pipeline-loops/pkg/apis/pipelineloop"
@@ -33,17 +38,22 @@ import (
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	runv1alpha1 "github.com/tektoncd/pipeline/pkg/apis/run/v1alpha1"
 	clientset "github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
 	runreconciler "github.com/tektoncd/pipeline/pkg/client/injection/reconciler/pipeline/v1alpha1/run"
 	listersalpha "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1alpha1"
 	listers "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1beta1"
 	"github.com/tektoncd/pipeline/pkg/names"
 	"github.com/tektoncd/pipeline/pkg/reconciler/events"
+	"github.com/tektoncd/pipeline/pkg/remote/oci"
 	"go.uber.org/zap"
 	"gomodules.xyz/jsonpatch/v2"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
 	"knative.dev/pkg/apis"
 	"knative.dev/pkg/logging"
 	pkgreconciler "knative.dev/pkg/reconciler"
@@ -58,10 +68,18 @@ const (
 
 	// pipelineLoopIterationLabelKey is the label identifier for the iteration number.  This label is added to the Run's PipelineRuns.
 	pipelineLoopIterationLabelKey = "/pipelineLoopIteration"
+
+	// pipelineLoopAttemptLabelKey is the label identifier for the attempt number.  This label is added to the PipelineRuns
+	// that retry an iteration.
+	pipelineLoopAttemptLabelKey = "/pipelineLoopAttempt"
+
+	// conditionResultName is the name of the Run result that tells whether a PipelineRun met the condition that ends the loop.
+	conditionResultName = "condition"
 )
 
 // Reconciler implements controller.Reconciler for Configuration resources.
 type Reconciler struct {
+	kubeClientSet         kubernetes.Interface
 	pipelineClientSet     clientset.Interface
 	pipelineloopClientSet pipelineloopclientset.Interface
 	runLister             listersalpha.RunLister
@@ -110,6 +128,14 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, run *v1alpha1.Run) pkgre
 
 	if run.IsDone() {
 		logger.Infof("Run %s/%s is done", run.Namespace, run.Name)
+		// The summaries of the PipelineRuns that succeeded last are recorded when the Run completes,
+		// so those PipelineRuns are only pruned once the Run is done.
+		status := &pipelineloopv1alpha1.PipelineLoopRunStatus{}
+		if err := run.Status.DecodeExtraFields(status); err == nil && status.PipelineLoopSpec != nil && status.PipelineLoopSpec.PruneSucceeded {
+			if err := c.deletePrunedPipelineRuns(ctx, logger, run, status); err != nil {
+				return fmt.Errorf("error pruning PipelineRuns for Run %s/%s: %w", run.Namespace, run.Name, err)
+			}
+		}
 		return nil
 	}
 
@@ -178,108 +204,190 @@ func (c *Reconciler) reconcile(ctx context.Context, run *v1alpha1.Run, status *p
 		return nil
 	}
 
-	// Update status of PipelineRuns.  Return the PipelineRun representing the highest loop iteration.
-	highestIteration, highestIterationPr, err := c.updatePipelineRunStatus(logger, run, status)
+	// Resolve the Pipeline once per Run and store its spec on the Run so that every iteration
+	// runs the same Pipeline, even if a bundle that contains it changes while the Run is in progress.
+	if status.PipelineSpec == nil {
+		pipelineSpec, err := c.getPipelineSpec(ctx, run, pipelineLoopSpec)
+		if err != nil {
+			run.Status.MarkRunFailed(pipelineloopv1alpha1.PipelineLoopRunReasonCouldntGetPipeline.String(),
+				"Error retrieving Pipeline for Run %s/%s: %s",
+				run.Namespace, run.Name, err)
+			return nil
+		}
+		status.PipelineSpec = pipelineSpec
+	}
+	pipelineSpec := status.PipelineSpec
+
+	// Check that the Run binds the workspaces that are isolated for each iteration.
+	for _, name := range pipelineLoopSpec.IsolatedWorkspaces {
+		if !isWorkspaceBound(run, name) {
+			run.Status.MarkRunFailed(pipelineloopv1alpha1.PipelineLoopRunReasonFailedValidation.String(),
+				"Isolated workspace %q is not bound by Run %s/%s", name, run.Namespace, run.Name)
+			return nil
+		}
+	}
+
+	// Compile the break condition that is evaluated against the results of the PipelineRuns.
+	var breakCondition cel.Program
+	if pipelineLoopSpec.BreakCondition != "" {
+		breakCondition, err = pipelineloopv1alpha1.CompileBreakCondition(pipelineLoopSpec.BreakCondition)
+		if err != nil {
+			run.Status.MarkRunFailed(pipelineloopv1alpha1.PipelineLoopRunReasonFailedValidation.String(),
+				"PipelineLoop %s/%s can't be Run; its break condition can't be compiled: %s",
+				pipelineLoopMeta.Namespace, pipelineLoopMeta.Name, err)
+			return nil
+		}
+	}
+
+	// Update status of PipelineRuns.  Return the highest iteration number processed so far,
+	// the PipelineRuns that are running, the PipelineRuns that have failed and
+	// whether a PipelineRun met the condition that ends the loop.
+	highestIteration, runningPrs, failedPrs, conditionMet, err := c.updatePipelineRunStatus(logger, run, status, breakCondition)
 	if err != nil {
 		return fmt.Errorf("error updating PipelineRun status for Run %s/%s: %w", run.Namespace, run.Name, err)
 	}
+	status.CurrentRunning = len(runningPrs)
 
-	// Check the status of the PipelineRun for the highest iteration.
-	if highestIterationPr != nil {
-		// If it's not done, wait for it to finish or cancel it if the run is cancelled.
-		if !highestIterationPr.IsDone() {
-			if run.IsCancelled() {
-				logger.Infof("Run %s/%s is cancelled.  Cancelling PipelineRun %s.", run.Namespace, run.Name, highestIterationPr.Name)
-				b, err := getCancelPatch()
-				if err != nil {
-					return fmt.Errorf("Failed to make patch to cancel PipelineRun %s: %v", highestIterationPr.Name, err)
-				}
-				if _, err := c.pipelineClientSet.TektonV1beta1().PipelineRuns(run.Namespace).Patch(ctx, highestIterationPr.Name, types.JSONPatchType, b, metav1.PatchOptions{}); err != nil {
-					run.Status.MarkRunRunning(pipelineloopv1alpha1.PipelineLoopRunReasonCouldntCancel.String(),
-						"Failed to patch PipelineRun `%s` with cancellation: %v", highestIterationPr.Name, err)
-					return nil
-				}
-				// Update status. It is still running until the PipelineRun is actually cancelled.
-				run.Status.MarkRunRunning(pipelineloopv1alpha1.PipelineLoopRunReasonRunning.String(),
-					"Cancelling PipelineRun %s", highestIterationPr.Name)
+	// Delete the PipelineRuns whose summary was recorded in the Run status by a prior reconcile, then record
+	// the summary of the PipelineRuns that succeeded since.  A PipelineRun is only deleted once the Run status
+	// that holds its summary has been updated, so its results survive a failure to update the Run.
+	if pipelineLoopSpec.PruneSucceeded {
+		if err := c.deletePrunedPipelineRuns(ctx, logger, run, status); err != nil {
+			return fmt.Errorf("error pruning PipelineRuns for Run %s/%s: %w", run.Namespace, run.Name, err)
+		}
+		summarizeSucceededPipelineRuns(logger, status)
+	}
+
+	// Check if the run was cancelled.  Cancel the running PipelineRuns and wait for them to finish.
+	if run.IsCancelled() {
+		if len(runningPrs) == 0 {
+			run.Status.MarkRunFailed(pipelineloopv1alpha1.PipelineLoopRunReasonCancelled.String(),
+				"Run %s/%s was cancelled",
+				run.Namespace, run.Name)
+			return nil
+		}
+		b, err := getCancelPatch()
+		if err != nil {
+			return fmt.Errorf("Failed to make patch to cancel PipelineRuns for Run %s: %v", run.Name, err)
+		}
+		prNames := make([]string, 0, len(runningPrs))
+		for _, pr := range runningPrs {
+			logger.Infof("Run %s/%s is cancelled.  Cancelling PipelineRun %s.", run.Namespace, run.Name, pr.Name)
+			if _, err := c.pipelineClientSet.TektonV1beta1().PipelineRuns(run.Namespace).Patch(ctx, pr.Name, types.JSONPatchType, b, metav1.PatchOptions{}); err != nil {
+				run.Status.MarkRunRunning(pipelineloopv1alpha1.PipelineLoopRunReasonCouldntCancel.String(),
+					"Failed to patch PipelineRun `%s` with cancellation: %v", pr.Name, err)
 				return nil
 			}
-			run.Status.MarkRunRunning(pipelineloopv1alpha1.PipelineLoopRunReasonRunning.String(),
-				"Iterations completed: %d", highestIteration-1)
-			return nil
+			prNames = append(prNames, pr.Name)
 		}
+		// Update status. It is still running until the PipelineRuns are actually cancelled.
+		run.Status.MarkRunRunning(pipelineloopv1alpha1.PipelineLoopRunReasonRunning.String(),
+			"Cancelling PipelineRun %s", strings.Join(prNames, ", "))
+		return nil
+	}
 
-		if !highestIterationPr.Status.GetCondition(apis.ConditionSucceeded).IsTrue() {
-			if run.IsCancelled() {
-				run.Status.MarkRunFailed(pipelineloopv1alpha1.PipelineLoopRunReasonCancelled.String(),
-					"Run %s/%s was cancelled",
-					run.Namespace, run.Name)
-			} else {
-				run.Status.MarkRunFailed(pipelineloopv1alpha1.PipelineLoopRunReasonFailed.String(),
-					"PipelineRun %s has failed", highestIterationPr.Name)
-			}
+	// Retry the failed PipelineRuns whose iterations have retries left.
+	retriesExhausted := failedPrs[:0]
+	for _, pr := range failedPrs {
+		attempt := getPipelineRunAttempt(pr)
+		if attempt > pipelineLoopSpec.Retries {
+			retriesExhausted = append(retriesExhausted, pr)
+			continue
+		}
+		iteration := status.PipelineRuns[pr.Name].Iteration
+		resultParams, err := getResultParams(pipelineLoopSpec, status, iteration)
+		if err != nil {
+			run.Status.MarkRunFailed(pipelineloopv1alpha1.PipelineLoopRunReasonFailed.String(),
+				"Cannot pass results to iteration %d: %s", iteration, err)
 			return nil
 		}
+		logger.Infof("Retrying iteration %d of Run %s/%s after PipelineRun %s failed", iteration, run.Namespace, run.Name, pr.Name)
+		retryPr, err := c.createPipelineRun(ctx, logger, pipelineLoopSpec, pipelineSpec, run, iteration, attempt+1, resultParams)
+		if err != nil {
+			return fmt.Errorf("error retrying PipelineRun %s from Run %s: %w", pr.Name, run.Name, err)
+		}
+		status.PipelineRuns[pr.Name].Retried = true
+		status.PipelineRuns[retryPr.Name] = &pipelineloopv1alpha1.PipelineLoopPipelineRunStatus{
+			Iteration: iteration,
+			Attempt:   attempt + 1,
+			Status:    &retryPr.Status,
+		}
+		runningPrs = append(runningPrs, retryPr)
+	}
+	failedPrs = retriesExhausted
+	status.CurrentRunning = len(runningPrs)
 
-		// Mark run successful if the condition are met.
-		// if the last loop task is skipped, but the highestIterationPr successed. Mark run success.
-		// lastLoopTask := highestIterationPr.ObjectMeta.Annotations["last-loop-task"]
-		lastLoopTask := ""
-		for key, val := range run.ObjectMeta.Labels {
-			if key == "last-loop-task" {
-				lastLoopTask = val
-			}
+	// A failed PipelineRun or a met condition stops the loop.  Running PipelineRuns are allowed to finish.
+	if len(failedPrs) != 0 || conditionMet {
+		if len(runningPrs) != 0 {
+			run.Status.MarkRunRunning(pipelineloopv1alpha1.PipelineLoopRunReasonRunning.String(),
+				"Iterations completed: %d", highestIteration-len(runningPrs))
+			return nil
 		}
-		if lastLoopTask != "" {
-			skippedTaskList := highestIterationPr.Status.SkippedTasks
-			for _, task := range skippedTaskList {
-				if task.Name == lastLoopTask {
-					// Mark run successful and stop the loop pipelinerun
-					run.Status.MarkRunSucceeded(pipelineloopv1alpha1.PipelineLoopRunReasonSucceeded.String(),
-						"PipelineRuns completed successfully with the conditions are met")
-					run.Status.Results = []v1beta1.TaskRunResult{{
-						Name:  "condition",
-						Value: "pass",
-					}}
-					return nil
-				}
-			}
+		if len(failedPrs) != 0 {
+			run.Status.MarkRunFailed(pipelineloopv1alpha1.PipelineLoopRunReasonFailed.String(),
+				"PipelineRun %s has failed", failedPrs[0].Name)
+			run.Status.Results = getRunResults(status, totalIterations)
+			return nil
 		}
+		// Mark run successful and stop the loop pipelinerun
+		run.Status.MarkRunSucceeded(pipelineloopv1alpha1.PipelineLoopRunReasonSucceeded.String(),
+			"PipelineRuns completed successfully with the conditions are met")
+		run.Status.Results = append([]runv1alpha1.RunResult{{
+			Name:  conditionResultName,
+			Value: "pass",
+		}}, getRunResults(status, totalIterations)...)
+		return nil
 	}
 
-	// Move on to the next iteration (or the first iteration if there was no PipelineRun).
-	// Check if the Run is done.
-	nextIteration := highestIteration + 1
-	if nextIteration > totalIterations {
+	// Check if the Run is done.  PipelineRuns were created for all iterations and all of them are done.
+	if highestIteration >= totalIterations {
+		if len(runningPrs) != 0 {
+			run.Status.MarkRunRunning(pipelineloopv1alpha1.PipelineLoopRunReasonRunning.String(),
+				"Iterations completed: %d", highestIteration-len(runningPrs))
+			return nil
+		}
 		run.Status.MarkRunSucceeded(pipelineloopv1alpha1.PipelineLoopRunReasonSucceeded.String(),
 			"All PipelineRuns completed successfully")
-		run.Status.Results = []v1beta1.TaskRunResult{{
-			Name:  "condition",
+		run.Status.Results = append([]runv1alpha1.RunResult{{
+			Name:  conditionResultName,
 			Value: "fail",
-		}}
-		return nil
-	}
-	// Before starting up another PipelineRun, check if the run was cancelled.
-	if run.IsCancelled() {
-		run.Status.MarkRunFailed(pipelineloopv1alpha1.PipelineLoopRunReasonCancelled.String(),
-			"Run %s/%s was cancelled",
-			run.Namespace, run.Name)
+		}}, getRunResults(status, totalIterations)...)
 		return nil
 	}
 
-	// Create a PipelineRun to run this iteration.
-	pr, err := c.createPipelineRun(ctx, logger, pipelineLoopSpec, run, nextIteration)
-	if err != nil {
-		return fmt.Errorf("error creating PipelineRun from Run %s: %w", run.Name, err)
+	// Create PipelineRuns for the next iterations.  Continue creating them until the concurrency
+	// limit is reached.  If the limit is unspecified, it defaults to 1 (sequential execution).
+	// If the limit is 0 or negative, then PipelineRuns are created for all iterations at once.
+	nextIteration := highestIteration + 1
+	concurrency := 1
+	if pipelineLoopSpec.Concurrency != nil {
+		concurrency = *pipelineLoopSpec.Concurrency
 	}
-
-	status.PipelineRuns[pr.Name] = &pipelineloopv1alpha1.PipelineLoopPipelineRunStatus{
-		Iteration: nextIteration,
-		Status:    &pr.Status,
+	for nextIteration <= totalIterations && (concurrency <= 0 || status.CurrentRunning < concurrency) {
+		// Pass the results of the previous iteration to the next iteration.
+		resultParams, err := getResultParams(pipelineLoopSpec, status, nextIteration)
+		if err != nil {
+			run.Status.MarkRunFailed(pipelineloopv1alpha1.PipelineLoopRunReasonFailed.String(),
+				"Cannot pass results to iteration %d: %s", nextIteration, err)
+			return nil
+		}
+		// Create a PipelineRun to run the next iteration.
+		pr, err := c.createPipelineRun(ctx, logger, pipelineLoopSpec, pipelineSpec, run, nextIteration, 1, resultParams)
+		if err != nil {
+			return fmt.Errorf("error creating PipelineRun from Run %s: %w", run.Name, err)
+		}
+		status.PipelineRuns[pr.Name] = &pipelineloopv1alpha1.PipelineLoopPipelineRunStatus{
+			Iteration: nextIteration,
+			Attempt:   1,
+			Status:    &pr.Status,
+		}
+		status.CurrentRunning++
+		nextIteration++
 	}
 
 	run.Status.MarkRunRunning(pipelineloopv1alpha1.PipelineLoopRunReasonRunning.String(),
-		"Iterations completed: %d", highestIteration)
+		"Iterations completed: %d", nextIteration-1-status.CurrentRunning)
 
 	return nil
 }
@@ -312,54 +420,104 @@ func (c *Reconciler) getPipelineLoop(ctx context.Context, run *v1alpha1.Run) (*m
 	return &pipelineLoopMeta, &pipelineLoopSpec, nil
 }
 
-func (c *Reconciler) createPipelineRun(ctx context.Context, logger *zap.SugaredLogger, tls *pipelineloopv1alpha1.PipelineLoopSpec, run *v1alpha1.Run, iteration int) (*v1beta1.PipelineRun, error) {
+// getPipelineSpec resolves the Pipeline that the PipelineLoop runs, either inline, from the cluster or from a bundle.
+func (c *Reconciler) getPipelineSpec(ctx context.Context, run *v1alpha1.Run, tls *pipelineloopv1alpha1.PipelineLoopSpec) (*v1beta1.PipelineSpec, error) {
+	if tls.PipelineSpec != nil {
+		return tls.PipelineSpec, nil
+	}
+	if tls.PipelineRef.Bundle != "" {
+		return c.getBundlePipelineSpec(ctx, run, tls.PipelineRef)
+	}
+	// Use the k8 client to get the Pipeline rather than a lister for the same reason as getPipelineLoop().
+	p, err := c.pipelineClientSet.TektonV1beta1().Pipelines(run.Namespace).Get(ctx, tls.PipelineRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &p.Spec, nil
+}
+
+// getBundlePipelineSpec fetches the Pipeline referenced by a pipelineRef from a Tekton bundle.
+// The bundle is pulled with the credentials of the Run's service account and image pull secrets,
+// as Tekton does for the bundles referenced by PipelineRuns.
+func (c *Reconciler) getBundlePipelineSpec(ctx context.Context, run *v1alpha1.Run, pipelineRef *v1beta1.PipelineRef) (*v1beta1.PipelineSpec, error) {
+	var imagePullSecrets []string
+	if run.Spec.PodTemplate != nil {
+		for _, s := range run.Spec.PodTemplate.ImagePullSecrets {
+			imagePullSecrets = append(imagePullSecrets, s.Name)
+		}
+	}
+	kc, err := k8schain.New(ctx, c.kubeClientSet, k8schain.Options{
+		Namespace:          run.Namespace,
+		ServiceAccountName: run.Spec.ServiceAccountName,
+		ImagePullSecrets:   imagePullSecrets,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get keychain: %w", err)
+	}
+	resolver := oci.NewResolver(pipelineRef.Bundle, kc)
+	obj, err := resolver.Get("pipeline", pipelineRef.Name)
+	if err != nil {
+		return nil, err
+	}
+	pipeline, ok := obj.(v1beta1.PipelineObject)
+	if !ok {
+		return nil, fmt.Errorf("bundle %s contains %s %s which is not a v1beta1 Pipeline",
+			pipelineRef.Bundle, obj.GetObjectKind().GroupVersionKind(), pipelineRef.Name)
+	}
+	pipelineSpec := pipeline.PipelineSpec()
+	return &pipelineSpec, nil
+}
+
+// createPipelineRun creates a PipelineRun for an attempt at an iteration.  Attempts after the first retry the iteration.
+func (c *Reconciler) createPipelineRun(ctx context.Context, logger *zap.SugaredLogger, tls *pipelineloopv1alpha1.PipelineLoopSpec, pipelineSpec *v1beta1.PipelineSpec, run *v1alpha1.Run, iteration int, attempt int, resultParams []v1beta1.Param) (*v1beta1.PipelineRun, error) {
 
 	// Create name for PipelineRun from Run name plus iteration number.
+	// The random suffix gives each attempt at the iteration a new name.
 	prName := names.SimpleNameGenerator.RestrictLengthWithRandomSuffix(fmt.Sprintf("%s-%s", run.Name, fmt.Sprintf("%05d", iteration)))
 
+	prLabels := getPipelineRunLabels(run, strconv.Itoa(iteration))
+	if attempt > 1 {
+		prLabels[pipelineloop.GroupName+pipelineLoopAttemptLabelKey] = strconv.Itoa(attempt)
+	}
+
+	// The results passed from the previous iteration replace the Run's parameters with the same name.
+	var resultParamNames []string
+	for _, p := range resultParams {
+		resultParamNames = append(resultParamNames, p.Name)
+	}
+	var params []v1beta1.Param
+	for _, p := range getParameters(run, tls, iteration) {
+		if _, found := Find(resultParamNames, p.Name); !found {
+			params = append(params, p)
+		}
+	}
+	params = append(params, resultParams...)
+
 	pr := &v1beta1.PipelineRun{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:            prName,
 			Namespace:       run.Namespace,
 			OwnerReferences: []metav1.OwnerReference{run.GetOwnerReference()},
-			Labels:          getPipelineRunLabels(run, strconv.Itoa(iteration)),
+			Labels:          prLabels,
 			Annotations:     getPipelineRunAnnotations(run),
 		},
 		Spec: v1beta1.PipelineRunSpec{
-			Params:             getParameters(run, tls, iteration),
+			Params:             params,
 			Timeout:            tls.Timeout,
-			ServiceAccountName: "",  // TODO: Implement service account name
-			PodTemplate:        nil, // TODO: Implement pod template
+			ServiceAccountName: run.Spec.ServiceAccountName,
+			PodTemplate:        run.Spec.PodTemplate,
+			Workspaces:         getWorkspaces(run, tls, iteration),
 		}}
 
-	if tls.PipelineRef != nil {
-		pr.Spec.PipelineRef = &v1beta1.PipelineRef{
-			Name: tls.PipelineRef.Name,
-			// Kind: tls.PipelineRef.Kind,
-		}
-	} else if tls.PipelineSpec != nil {
-		pr.Spec.PipelineSpec = tls.PipelineSpec
-	}
+	// The Pipeline resolved for the Run is embedded so that every iteration runs the same Pipeline,
+	// even if the Pipeline or the bundle that contains it changes while the Run is in progress.
+	pr.Spec.PipelineSpec = pipelineSpec
 
 	logger.Infof("Creating a new PipelineRun object %s", prName)
 	return c.pipelineClientSet.TektonV1beta1().PipelineRuns(run.Namespace).Create(ctx, pr, metav1.CreateOptions{})
 
 }
 
-// func (c *Reconciler) retryPipelineRun(ctx context.Context, tr *v1beta1.PipelineRun) (*v1beta1.PipelineRun, error) {
-// 	newStatus := *tr.Status.DeepCopy()
-// 	newStatus.RetriesStatus = nil
-// 	tr.Status.RetriesStatus = append(tr.Status.RetriesStatus, newStatus)
-// 	tr.Status.StartTime = nil
-// 	tr.Status.CompletionTime = nil
-// 	tr.Status.PodName = ""
-// 	tr.Status.SetCondition(&apis.Condition{
-// 		Type:   apis.ConditionSucceeded,
-// 		Status: corev1.ConditionUnknown,
-// 	})
-// 	return c.pipelineClientSet.TektonV1beta1().PipelineRuns(tr.Namespace).UpdateStatus(ctx, tr, metav1.UpdateOptions{})
-// }
-
 func (c *Reconciler) updateLabelsAndAnnotations(ctx context.Context, run *v1alpha1.Run) error {
 	newRun, err := c.runLister.Runs(run.Namespace).Get(run.Name)
 	if err != nil {
@@ -382,20 +540,28 @@ func (c *Reconciler) updateLabelsAndAnnotations(ctx context.Context, run *v1alph
 	return nil
 }
 
-func (c *Reconciler) updatePipelineRunStatus(logger *zap.SugaredLogger, run *v1alpha1.Run, status *pipelineloopv1alpha1.PipelineLoopRunStatus) (int, *v1beta1.PipelineRun, error) {
-	highestIteration := 0
-	var highestIterationPr *v1beta1.PipelineRun = nil
+func (c *Reconciler) updatePipelineRunStatus(logger *zap.SugaredLogger, run *v1alpha1.Run, status *pipelineloopv1alpha1.PipelineLoopRunStatus, breakCondition cel.Program) (
+	highestIteration int, runningPrs []*v1beta1.PipelineRun, failedPrs []*v1beta1.PipelineRun, conditionMet bool, err error) {
 	if status.PipelineRuns == nil {
 		status.PipelineRuns = make(map[string]*pipelineloopv1alpha1.PipelineLoopPipelineRunStatus)
 	}
 	pipelineRunLabels := getPipelineRunLabels(run, "")
-	pipelineRuns, err := c.pipelineRunLister.PipelineRuns(run.Namespace).List(labels.SelectorFromSet(pipelineRunLabels))
+	listedPipelineRuns, err := c.pipelineRunLister.PipelineRuns(run.Namespace).List(labels.SelectorFromSet(pipelineRunLabels))
 	if err != nil {
-		return 0, nil, fmt.Errorf("could not list PipelineRuns %#v", err)
+		return 0, nil, nil, false, fmt.Errorf("could not list PipelineRuns %#v", err)
 	}
-	if pipelineRuns == nil || len(pipelineRuns) == 0 {
-		return 0, nil, nil
+	// The summary recorded for a pruned PipelineRun is final, even while the PipelineRun waits to be deleted.
+	pipelineRuns := make([]*v1beta1.PipelineRun, 0, len(listedPipelineRuns))
+	for _, pr := range listedPipelineRuns {
+		if prs, ok := status.PipelineRuns[pr.Name]; !ok || !prs.Pruned {
+			pipelineRuns = append(pipelineRuns, pr)
+		}
 	}
+	// The loop ends early when the last loop task of a successful PipelineRun is skipped.
+	lastLoopTask := run.ObjectMeta.Labels["last-loop-task"]
+	iterations := make(map[string]int, len(pipelineRuns))
+	// The latest attempt at each iteration decides the state of the iteration.
+	latestAttempts := make(map[int]*v1beta1.PipelineRun)
 	for _, pr := range pipelineRuns {
 		lbls := pr.GetLabels()
 		iterationStr := lbls[pipelineloop.GroupName+pipelineLoopIterationLabelKey]
@@ -404,18 +570,139 @@ func (c *Reconciler) updatePipelineRunStatus(logger *zap.SugaredLogger, run *v1a
 			run.Status.MarkRunFailed(pipelineloopv1alpha1.PipelineLoopRunReasonFailedValidation.String(),
 				"Error converting iteration number in PipelineRun %s:  %#v", pr.Name, err)
 			logger.Errorf("Error converting iteration number in PipelineRun %s:  %#v", pr.Name, err)
-			return 0, nil, nil
+			return 0, nil, nil, false, nil
 		}
+		iterations[pr.Name] = iteration
+		attempt := getPipelineRunAttempt(pr)
 		status.PipelineRuns[pr.Name] = &pipelineloopv1alpha1.PipelineLoopPipelineRunStatus{
 			Iteration: iteration,
+			Attempt:   attempt,
 			Status:    &pr.Status,
 		}
+		if latest, ok := latestAttempts[iteration]; !ok || getPipelineRunAttempt(latest) < attempt {
+			latestAttempts[iteration] = pr
+		}
 		if iteration > highestIteration {
 			highestIteration = iteration
-			highestIterationPr = pr
 		}
 	}
-	return highestIteration, highestIterationPr, nil
+	// Pruned PipelineRuns no longer exist but their iterations succeeded and still count.
+	prunedAttempts := make(map[int]int)
+	for name, prs := range status.PipelineRuns {
+		if !prs.Pruned {
+			continue
+		}
+		prunedAttempts[prs.Iteration] = prs.Attempt
+		if prs.Iteration > highestIteration {
+			highestIteration = prs.Iteration
+		}
+		if isConditionMet(logger, lastLoopTask, breakCondition, name, prs.Status) {
+			conditionMet = true
+		}
+	}
+	for _, pr := range pipelineRuns {
+		if latestAttempts[iterations[pr.Name]] != pr || prunedAttempts[iterations[pr.Name]] > getPipelineRunAttempt(pr) {
+			status.PipelineRuns[pr.Name].Retried = true
+			continue
+		}
+		switch {
+		case !pr.IsDone():
+			runningPrs = append(runningPrs, pr)
+		case !pr.Status.GetCondition(apis.ConditionSucceeded).IsTrue():
+			failedPrs = append(failedPrs, pr)
+		default:
+			if isConditionMet(logger, lastLoopTask, breakCondition, pr.Name, &pr.Status) {
+				conditionMet = true
+			}
+		}
+	}
+	// Order the PipelineRuns by iteration so that the Run reports them consistently.
+	byIteration := func(prs []*v1beta1.PipelineRun) func(i, j int) bool {
+		return func(i, j int) bool { return iterations[prs[i].Name] < iterations[prs[j].Name] }
+	}
+	sort.Slice(runningPrs, byIteration(runningPrs))
+	sort.Slice(failedPrs, byIteration(failedPrs))
+	return highestIteration, runningPrs, failedPrs, conditionMet, nil
+}
+
+// summarizeSucceededPipelineRuns replaces the status of the successful PipelineRuns of the Run with
+// a summary and marks them to be pruned.
+func summarizeSucceededPipelineRuns(logger *zap.SugaredLogger, status *pipelineloopv1alpha1.PipelineLoopRunStatus) {
+	for name, prs := range status.PipelineRuns {
+		if prs.Pruned || prs.Retried || prs.Status == nil || !prs.Status.GetCondition(apis.ConditionSucceeded).IsTrue() {
+			continue
+		}
+		logger.Infof("Recording the summary of PipelineRun %s of iteration %d before pruning it", name, prs.Iteration)
+		prs.Pruned = true
+		prs.Status = summarizePipelineRunStatus(prs.Status)
+	}
+}
+
+// deletePrunedPipelineRuns deletes the PipelineRuns whose summary is recorded in the Run status.
+func (c *Reconciler) deletePrunedPipelineRuns(ctx context.Context, logger *zap.SugaredLogger, run *v1alpha1.Run, status *pipelineloopv1alpha1.PipelineLoopRunStatus) error {
+	for name, prs := range status.PipelineRuns {
+		if !prs.Pruned {
+			continue
+		}
+		if _, err := c.pipelineRunLister.PipelineRuns(run.Namespace).Get(name); k8serrors.IsNotFound(err) {
+			continue
+		}
+		logger.Infof("Pruning PipelineRun %s of iteration %d", name, prs.Iteration)
+		err := c.pipelineClientSet.TektonV1beta1().PipelineRuns(run.Namespace).Delete(ctx, name, metav1.DeleteOptions{})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("could not delete PipelineRun %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// summarizePipelineRunStatus keeps the parts of a PipelineRun status that the loop still needs
+// once the PipelineRun is deleted.
+func summarizePipelineRunStatus(status *v1beta1.PipelineRunStatus) *v1beta1.PipelineRunStatus {
+	summary := &v1beta1.PipelineRunStatus{}
+	summary.SetCondition(status.GetCondition(apis.ConditionSucceeded))
+	summary.StartTime = status.StartTime
+	summary.CompletionTime = status.CompletionTime
+	summary.PipelineResults = status.PipelineResults
+	summary.SkippedTasks = status.SkippedTasks
+	return summary
+}
+
+// isConditionMet returns whether a successful PipelineRun ends the loop, either because it skipped
+// the last loop task or because its results meet the break condition.
+func isConditionMet(logger *zap.SugaredLogger, lastLoopTask string, breakCondition cel.Program, name string, status *v1beta1.PipelineRunStatus) bool {
+	for _, task := range status.SkippedTasks {
+		if lastLoopTask != "" && task.Name == lastLoopTask {
+			return true
+		}
+	}
+	return breakCondition != nil && isBreakConditionMet(logger, breakCondition, name, status)
+}
+
+// isBreakConditionMet evaluates the break condition against the results of a successful PipelineRun.
+// A condition that can't be evaluated, for example because it references a result that the
+// PipelineRun didn't produce, is not met.
+func isBreakConditionMet(logger *zap.SugaredLogger, breakCondition cel.Program, name string, status *v1beta1.PipelineRunStatus) bool {
+	results := make(map[string]string, len(status.PipelineResults))
+	for _, result := range status.PipelineResults {
+		results[result.Name] = result.Value
+	}
+	out, _, err := breakCondition.Eval(map[string]interface{}{"results": results})
+	if err != nil {
+		logger.Warnf("Break condition couldn't be evaluated against the results of PipelineRun %s: %v", name, err)
+		return false
+	}
+	met, ok := out.Value().(bool)
+	return ok && met
+}
+
+// getPipelineRunAttempt returns the attempt at its iteration that a PipelineRun runs.
+func getPipelineRunAttempt(pr *v1beta1.PipelineRun) int {
+	attempt, err := strconv.Atoi(pr.GetLabels()[pipelineloop.GroupName+pipelineLoopAttemptLabelKey])
+	if err != nil || attempt < 1 {
+		return 1
+	}
+	return attempt
 }
 
 func getCancelPatch() ([]byte, error) {
@@ -565,6 +852,122 @@ func getParameters(run *v1alpha1.Run, tls *pipelineloopv1alpha1.PipelineLoopSpec
 	return out
 }
 
+// getRunResults collects the results of the PipelineRuns into Run results.  Each result is published
+// as a JSON array with the value of each iteration.  Iterations that failed or were never run are null.
+func getRunResults(status *pipelineloopv1alpha1.PipelineLoopRunStatus, totalIterations int) []runv1alpha1.RunResult {
+	// Index the latest attempts by iteration and find the names of all results declared or produced by them.
+	pipelineRunsByIteration := make(map[int]*pipelineloopv1alpha1.PipelineLoopPipelineRunStatus, len(status.PipelineRuns))
+	resultNames := sets.NewString()
+	for _, prs := range status.PipelineRuns {
+		if prs.Status == nil || prs.Retried {
+			continue
+		}
+		pipelineRunsByIteration[prs.Iteration] = prs
+		if prs.Status.PipelineSpec != nil {
+			for _, r := range prs.Status.PipelineSpec.Results {
+				resultNames.Insert(r.Name)
+			}
+		}
+		for _, r := range prs.Status.PipelineResults {
+			resultNames.Insert(r.Name)
+		}
+	}
+	// The condition result is reserved for the loop itself.
+	resultNames.Delete(conditionResultName)
+
+	// Collect the value of each result for each successful iteration.
+	values := make(map[string][]*string, resultNames.Len())
+	for _, name := range resultNames.List() {
+		values[name] = make([]*string, totalIterations)
+	}
+	for iteration := 1; iteration <= totalIterations; iteration++ {
+		prs, ok := pipelineRunsByIteration[iteration]
+		if !ok || !prs.Status.GetCondition(apis.ConditionSucceeded).IsTrue() {
+			continue
+		}
+		for _, r := range prs.Status.PipelineResults {
+			if _, ok := values[r.Name]; ok {
+				value := r.Value
+				values[r.Name][iteration-1] = &value
+			}
+		}
+	}
+
+	var results []runv1alpha1.RunResult
+	for _, name := range resultNames.List() {
+		b, _ := json.Marshal(values[name])
+		results = append(results, runv1alpha1.RunResult{Name: name, Value: string(b)})
+	}
+	return results
+}
+
+// getResultParams returns the parameters that pass the results of the previous iteration to an iteration.
+// The first iteration gets the seed values.
+func getResultParams(tls *pipelineloopv1alpha1.PipelineLoopSpec, status *pipelineloopv1alpha1.PipelineLoopRunStatus, iteration int) ([]v1beta1.Param, error) {
+	if len(tls.ResultParams) == 0 {
+		return nil, nil
+	}
+	var out []v1beta1.Param
+	if iteration == 1 {
+		for _, rp := range tls.ResultParams {
+			out = append(out, v1beta1.Param{
+				Name:  rp.Param,
+				Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: rp.Seed},
+			})
+		}
+		return out, nil
+	}
+	// The latest attempt at the previous iteration holds the results.
+	var previousName string
+	var previous *pipelineloopv1alpha1.PipelineLoopPipelineRunStatus
+	for name, prStatus := range status.PipelineRuns {
+		if prStatus.Iteration == iteration-1 && !prStatus.Retried {
+			previousName, previous = name, prStatus
+		}
+	}
+	if previous == nil || previous.Status == nil {
+		return nil, fmt.Errorf("the PipelineRun of iteration %d was not found", iteration-1)
+	}
+	results := make(map[string]string, len(previous.Status.PipelineResults))
+	for _, result := range previous.Status.PipelineResults {
+		results[result.Name] = result.Value
+	}
+	for _, rp := range tls.ResultParams {
+		value, ok := results[rp.Result]
+		if !ok {
+			return nil, fmt.Errorf("PipelineRun %s of iteration %d didn't produce the result %q", previousName, iteration-1, rp.Result)
+		}
+		out = append(out, v1beta1.Param{
+			Name:  rp.Param,
+			Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: value},
+		})
+	}
+	return out, nil
+}
+
+// getWorkspaces returns the workspace bindings of the Run for the PipelineRun of an iteration.
+// The isolated workspaces are bound with a separate subPath for each iteration.
+func getWorkspaces(run *v1alpha1.Run, tls *pipelineloopv1alpha1.PipelineLoopSpec, iteration int) []v1beta1.WorkspaceBinding {
+	var out []v1beta1.WorkspaceBinding
+	for _, ws := range run.Spec.Workspaces {
+		binding := ws.DeepCopy()
+		if _, found := Find(tls.IsolatedWorkspaces, ws.Name); found {
+			binding.SubPath = path.Join(ws.SubPath, fmt.Sprintf("iteration-%d", iteration))
+		}
+		out = append(out, *binding)
+	}
+	return out
+}
+
+func isWorkspaceBound(run *v1alpha1.Run, name string) bool {
+	for _, ws := range run.Spec.Workspaces {
+		if ws.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 func getPipelineRunAnnotations(run *v1alpha1.Run) map[string]string {
 	// Propagate annotations from Run to PipelineRun.
 	annotations := make(map[string]string, len(run.ObjectMeta.Annotations)+1)
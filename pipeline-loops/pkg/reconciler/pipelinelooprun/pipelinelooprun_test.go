@@ -19,12 +19,16 @@ package pipelinelooprun
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/go-containerregistry/pkg/registry"
 	"github.com/tektoncd/experimental/pipeline-loops/pkg/apis/pipelineloop"
 	pipelineloopv1alpha1 "github.com/tektoncd/experimental/pipeline-loops/pkg/apis/pipelineloop/v1alpha1"
 	fakeclient "github.com/tektoncd/experimental/pipeline-loops/pkg/client/injection/client/fake"
@@ -32,8 +36,10 @@ import (
 	"github.com/tektoncd/experimental/pipeline-loops/test"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	runv1alpha1 "github.com/tektoncd/pipeline/pkg/apis/run/v1alpha1"
 	ttesting "github.com/tektoncd/pipeline/pkg/reconciler/testing"
 	"github.com/tektoncd/pipeline/pkg/system"
+	ptest "github.com/tektoncd/pipeline/test"
 	"github.com/tektoncd/pipeline/test/diff"
 	"github.com/tektoncd/pipeline/test/names"
 	corev1 "k8s.io/api/core/v1"
@@ -42,7 +48,7 @@ import (
 	ktesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/record"
 	"knative.dev/pkg/apis"
-	"knative.dev/pkg/configmap"
+	cminformer "knative.dev/pkg/configmap/informer"
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/logging"
 	"knative.dev/pkg/reconciler"
@@ -70,6 +76,61 @@ func requestCancel(run *v1alpha1.Run) *v1alpha1.Run {
 	return runWithCancelStatus
 }
 
+func withConcurrency(pl *pipelineloopv1alpha1.PipelineLoop, concurrency int) *pipelineloopv1alpha1.PipelineLoop {
+	pipelineLoopWithConcurrency := pl.DeepCopy()
+	pipelineLoopWithConcurrency.Spec.Concurrency = &concurrency
+	return pipelineLoopWithConcurrency
+}
+
+func withRetries(pl *pipelineloopv1alpha1.PipelineLoop, retries int) *pipelineloopv1alpha1.PipelineLoop {
+	pipelineLoopWithRetries := pl.DeepCopy()
+	pipelineLoopWithRetries.Spec.Retries = retries
+	return pipelineLoopWithRetries
+}
+
+func withBreakCondition(pl *pipelineloopv1alpha1.PipelineLoop, breakCondition string) *pipelineloopv1alpha1.PipelineLoop {
+	pipelineLoopWithBreakCondition := pl.DeepCopy()
+	pipelineLoopWithBreakCondition.Spec.BreakCondition = breakCondition
+	return pipelineLoopWithBreakCondition
+}
+
+func withResultParams(pl *pipelineloopv1alpha1.PipelineLoop, resultParams ...pipelineloopv1alpha1.PipelineLoopResultParam) *pipelineloopv1alpha1.PipelineLoop {
+	pipelineLoopWithResultParams := pl.DeepCopy()
+	pipelineLoopWithResultParams.Spec.ResultParams = resultParams
+	return pipelineLoopWithResultParams
+}
+
+func withResults(pr *v1beta1.PipelineRun, results map[string]string) *v1beta1.PipelineRun {
+	prWithResults := pr.DeepCopy()
+	for name, value := range results {
+		prWithResults.Status.PipelineResults = append(prWithResults.Status.PipelineResults, v1beta1.PipelineRunResult{
+			Name:  name,
+			Value: value,
+		})
+	}
+	return prWithResults
+}
+
+func getCreatedPipelineRuns(clients test.Clients) []*v1beta1.PipelineRun {
+	var createdPipelineRuns []*v1beta1.PipelineRun
+	for _, a := range clients.Pipeline.Actions() {
+		if a.GetVerb() == "create" {
+			if pr, ok := a.(ktesting.CreateAction).GetObject().(*v1beta1.PipelineRun); ok {
+				createdPipelineRuns = append(createdPipelineRuns, pr)
+			}
+		}
+	}
+	return createdPipelineRuns
+}
+
+// retryOf returns the PipelineRun that retries the iteration of pr for the given attempt.
+func retryOf(pr *v1beta1.PipelineRun, name string, attempt int) *v1beta1.PipelineRun {
+	retryPr := pr.DeepCopy()
+	retryPr.Name = name
+	retryPr.Labels["custom.tekton.dev/pipelineLoopAttempt"] = fmt.Sprint(attempt)
+	return retryPr
+}
+
 func running(tr *v1beta1.PipelineRun) *v1beta1.PipelineRun {
 	trWithStatus := tr.DeepCopy()
 	trWithStatus.Status.SetCondition(&apis.Condition{
@@ -132,7 +193,7 @@ func getPipelineLoopController(t *testing.T, d test.Data, pipelineloops []*pipel
 		}
 	}
 
-	configMapWatcher := configmap.NewInformedWatcher(c.Kube, system.GetNamespace())
+	configMapWatcher := cminformer.NewInformedWatcher(c.Kube, system.GetNamespace())
 	ctl := NewController(namespace)(ctx, configMapWatcher)
 
 	if la, ok := ctl.Reconciler.(reconciler.LeaderAware); ok {
@@ -609,7 +670,7 @@ var expectedPipelineRunIterationDict = &v1beta1.PipelineRun{
 		},
 	},
 	Spec: v1beta1.PipelineRunSpec{
-		PipelineRef: &v1beta1.PipelineRef{Name: "a-pipeline"},
+		PipelineSpec: &aPipeline.Spec,
 		Params: []v1beta1.Param{{
 			Name:  "current-item-subvar-a",
 			Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "1"},
@@ -620,6 +681,7 @@ var expectedPipelineRunIterationDict = &v1beta1.PipelineRun{
 			Name:  "additional-parameter",
 			Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "stuff"},
 		}},
+		ServiceAccountName: "default",
 	},
 }
 
@@ -645,7 +707,7 @@ var expectedPipelineRunIteration1 = &v1beta1.PipelineRun{
 		},
 	},
 	Spec: v1beta1.PipelineRunSpec{
-		PipelineRef: &v1beta1.PipelineRef{Name: "a-pipeline"},
+		PipelineSpec: &aPipeline.Spec,
 		Params: []v1beta1.Param{{
 			Name:  "current-item",
 			Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "item1"},
@@ -653,6 +715,7 @@ var expectedPipelineRunIteration1 = &v1beta1.PipelineRun{
 			Name:  "additional-parameter",
 			Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "stuff"},
 		}},
+		ServiceAccountName: "default",
 	},
 }
 
@@ -679,7 +742,7 @@ var expectedConditionPipelineRunIteration1 = &v1beta1.PipelineRun{
 		},
 	},
 	Spec: v1beta1.PipelineRunSpec{
-		PipelineRef: &v1beta1.PipelineRef{Name: "a-pipeline"},
+		PipelineSpec: &aPipeline.Spec,
 		Params: []v1beta1.Param{{
 			Name:  "current-item",
 			Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "item1"},
@@ -687,6 +750,7 @@ var expectedConditionPipelineRunIteration1 = &v1beta1.PipelineRun{
 			Name:  "additional-parameter",
 			Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "stuff"},
 		}},
+		ServiceAccountName: "default",
 	},
 }
 
@@ -712,7 +776,7 @@ var expectedPipelineRunIterateNumeric1 = &v1beta1.PipelineRun{
 		},
 	},
 	Spec: v1beta1.PipelineRunSpec{
-		PipelineRef: &v1beta1.PipelineRef{Name: "n-pipeline"},
+		PipelineSpec: &nPipeline.Spec,
 		Params: []v1beta1.Param{{
 			Name:  "additional-parameter",
 			Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "stuff"},
@@ -720,6 +784,7 @@ var expectedPipelineRunIterateNumeric1 = &v1beta1.PipelineRun{
 			Name:  "iteration",
 			Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "1"},
 		}},
+		ServiceAccountName: "default",
 	},
 }
 
@@ -746,7 +811,7 @@ var expectedPipelineRunIteration2 = &v1beta1.PipelineRun{
 		},
 	},
 	Spec: v1beta1.PipelineRunSpec{
-		PipelineRef: &v1beta1.PipelineRef{Name: "a-pipeline"},
+		PipelineSpec: &aPipeline.Spec,
 		Params: []v1beta1.Param{{
 			Name:  "current-item",
 			Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "item2"},
@@ -754,6 +819,7 @@ var expectedPipelineRunIteration2 = &v1beta1.PipelineRun{
 			Name:  "additional-parameter",
 			Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "stuff"},
 		}},
+		ServiceAccountName: "default",
 	},
 }
 
@@ -805,7 +871,8 @@ var expectedPipelineRunWithInlineTaskIteration1 = &v1beta1.PipelineRun{
 			Name:  "additional-parameter",
 			Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "stuff"},
 		}},
-		Timeout: &metav1.Duration{Duration: 5 * time.Minute},
+		Timeout:            &metav1.Duration{Duration: 5 * time.Minute},
+		ServiceAccountName: "default",
 	},
 }
 
@@ -1094,3 +1161,1073 @@ func TestReconcilePipelineLoopRunFailures(t *testing.T) {
 		})
 	}
 }
+
+func TestReconcilePipelineLoopRunWithConcurrency(t *testing.T) {
+	testcases := []struct {
+		name                   string
+		concurrency            int
+		run                    *v1alpha1.Run
+		pipelineruns           []*v1beta1.PipelineRun
+		expectedStatus         corev1.ConditionStatus
+		expectedReason         pipelineloopv1alpha1.PipelineLoopRunReason
+		expectedIterations     []string
+		expectedCurrentRunning int
+		expectedEvents         []string
+	}{{
+		name:                   "Reconcile a new run with a pipelineloop that explicitly requests sequential execution",
+		concurrency:            1,
+		run:                    runPipelineLoop,
+		expectedStatus:         corev1.ConditionUnknown,
+		expectedReason:         pipelineloopv1alpha1.PipelineLoopRunReasonRunning,
+		expectedIterations:     []string{"1"},
+		expectedCurrentRunning: 1,
+		expectedEvents:         []string{"Normal Started", "Normal Running Iterations completed: 0"},
+	}, {
+		name:                   "Reconcile a new run with a pipelineloop that allows limited concurrency",
+		concurrency:            2,
+		run:                    runPipelineLoop,
+		expectedStatus:         corev1.ConditionUnknown,
+		expectedReason:         pipelineloopv1alpha1.PipelineLoopRunReasonRunning,
+		expectedIterations:     []string{"1", "2"},
+		expectedCurrentRunning: 2,
+		expectedEvents:         []string{"Normal Started", "Normal Running Iterations completed: 0"},
+	}, {
+		name:                   "Reconcile a new run with a pipelineloop that allows unlimited concurrency",
+		concurrency:            0,
+		run:                    runPipelineLoop,
+		expectedStatus:         corev1.ConditionUnknown,
+		expectedReason:         pipelineloopv1alpha1.PipelineLoopRunReasonRunning,
+		expectedIterations:     []string{"1", "2"},
+		expectedCurrentRunning: 2,
+		expectedEvents:         []string{"Normal Started", "Normal Running Iterations completed: 0"},
+	}, {
+		name:                   "Reconcile a run that allows limited concurrency after the second PipelineRun has succeeded",
+		concurrency:            2,
+		run:                    loopRunning(runPipelineLoop),
+		pipelineruns:           []*v1beta1.PipelineRun{running(expectedPipelineRunIteration1), successful(expectedPipelineRunIteration2)},
+		expectedStatus:         corev1.ConditionUnknown,
+		expectedReason:         pipelineloopv1alpha1.PipelineLoopRunReasonRunning,
+		expectedCurrentRunning: 1,
+		expectedEvents:         []string{"Normal Running Iterations completed: 1"},
+	}, {
+		name:                   "Reconcile a run that allows limited concurrency after the first PipelineRun has failed but the other is still running",
+		concurrency:            2,
+		run:                    loopRunning(runPipelineLoop),
+		pipelineruns:           []*v1beta1.PipelineRun{failed(expectedPipelineRunIteration1), running(expectedPipelineRunIteration2)},
+		expectedStatus:         corev1.ConditionUnknown,
+		expectedReason:         pipelineloopv1alpha1.PipelineLoopRunReasonRunning,
+		expectedCurrentRunning: 1,
+		expectedEvents:         []string{"Normal Running Iterations completed: 1"},
+	}, {
+		name:           "Reconcile a run that allows limited concurrency after the first PipelineRun has failed and the other has succeeded",
+		concurrency:    2,
+		run:            loopRunning(runPipelineLoop),
+		pipelineruns:   []*v1beta1.PipelineRun{failed(expectedPipelineRunIteration1), successful(expectedPipelineRunIteration2)},
+		expectedStatus: corev1.ConditionFalse,
+		expectedReason: pipelineloopv1alpha1.PipelineLoopRunReasonFailed,
+		expectedEvents: []string{"Warning Failed PipelineRun " + expectedPipelineRunIteration1.Name + " has failed"},
+	}, {
+		name:                   "Reconcile a cancelled run while two PipelineRuns are running",
+		concurrency:            2,
+		run:                    requestCancel(loopRunning(runPipelineLoop)),
+		pipelineruns:           []*v1beta1.PipelineRun{running(expectedPipelineRunIteration1), running(expectedPipelineRunIteration2)},
+		expectedStatus:         corev1.ConditionUnknown,
+		expectedReason:         pipelineloopv1alpha1.PipelineLoopRunReasonRunning,
+		expectedCurrentRunning: 2,
+		expectedEvents:         []string{"Normal Running Cancelling PipelineRun " + expectedPipelineRunIteration1.Name + ", " + expectedPipelineRunIteration2.Name},
+	}}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			names.TestingSeed()
+
+			d := test.Data{
+				Runs:         []*v1alpha1.Run{tc.run},
+				Pipelines:    []*v1beta1.Pipeline{aPipeline},
+				PipelineRuns: tc.pipelineruns,
+			}
+			testAssets, _ := getPipelineLoopController(t, d, []*pipelineloopv1alpha1.PipelineLoop{withConcurrency(aPipelineLoop, tc.concurrency)})
+			clients := testAssets.Clients
+
+			if err := testAssets.Controller.Reconciler.Reconcile(ctx, getRunName(tc.run)); err != nil {
+				t.Fatalf("Error reconciling: %s", err)
+			}
+			reconciledRun, err := clients.Pipeline.TektonV1alpha1().Runs(tc.run.Namespace).Get(ctx, tc.run.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Error getting reconciled run from fake client: %s", err)
+			}
+			checkRunCondition(t, reconciledRun, tc.expectedStatus, tc.expectedReason)
+
+			// Verify that PipelineRuns were created for the expected iterations.
+			var createdIterations []string
+			for _, a := range clients.Pipeline.Actions() {
+				if a.GetVerb() == "create" {
+					if pr, ok := a.(ktesting.CreateAction).GetObject().(*v1beta1.PipelineRun); ok {
+						createdIterations = append(createdIterations, pr.Labels["custom.tekton.dev/pipelineLoopIteration"])
+					}
+				}
+			}
+			if d := cmp.Diff(tc.expectedIterations, createdIterations); d != "" {
+				t.Errorf("PipelineRuns were created for the wrong iterations. Diff %s", diff.PrintWantGot(d))
+			}
+
+			// Verify that the Run status tracks the number of running PipelineRuns.
+			status := &pipelineloopv1alpha1.PipelineLoopRunStatus{}
+			if err := reconciledRun.Status.DecodeExtraFields(status); err != nil {
+				t.Fatalf("DecodeExtraFields error: %v", err.Error())
+			}
+			if status.CurrentRunning != tc.expectedCurrentRunning {
+				t.Errorf("Expected %d running PipelineRuns in the Run status but found %d", tc.expectedCurrentRunning, status.CurrentRunning)
+			}
+			if len(status.PipelineRuns) != len(tc.pipelineruns)+len(tc.expectedIterations) {
+				t.Errorf("Expected Run status to include %d PipelineRuns but found %d", len(tc.pipelineruns)+len(tc.expectedIterations), len(status.PipelineRuns))
+			}
+
+			if err := checkEvents(testAssets.Recorder, tc.name, tc.expectedEvents); err != nil {
+				t.Errorf(err.Error())
+			}
+		})
+	}
+}
+
+func TestReconcilePipelineLoopRunWithRetries(t *testing.T) {
+	// The first attempt is renamed so that the seeded name generator doesn't reproduce its name for a retry.
+	first := expectedPipelineRunIteration1.DeepCopy()
+	first.Name = "run-pipelineloop-00001-first"
+	retry1 := retryOf(first, "run-pipelineloop-00001-retry1", 2)
+	retry2 := retryOf(first, "run-pipelineloop-00001-retry2", 3)
+
+	testcases := []struct {
+		name           string
+		retries        int
+		pipelineruns   []*v1beta1.PipelineRun
+		expectedStatus corev1.ConditionStatus
+		expectedReason pipelineloopv1alpha1.PipelineLoopRunReason
+		// expectedCreated holds the iteration and attempt labels of the PipelineRuns that are created.
+		expectedCreated [][2]string
+		// expectedAttempts holds the attempt and whether it was retried for each existing PipelineRun.
+		expectedAttempts map[string]pipelineloopv1alpha1.PipelineLoopPipelineRunStatus
+		expectedEvents   []string
+	}{{
+		name:            "Retry the first PipelineRun after it failed",
+		retries:         2,
+		pipelineruns:    []*v1beta1.PipelineRun{failed(first)},
+		expectedStatus:  corev1.ConditionUnknown,
+		expectedReason:  pipelineloopv1alpha1.PipelineLoopRunReasonRunning,
+		expectedCreated: [][2]string{{"1", "2"}},
+		expectedAttempts: map[string]pipelineloopv1alpha1.PipelineLoopPipelineRunStatus{
+			first.Name: {Iteration: 1, Attempt: 1, Retried: true},
+		},
+		expectedEvents: []string{"Normal Running Iterations completed: 0"},
+	}, {
+		name:            "Retry the first PipelineRun again after its retry failed",
+		retries:         2,
+		pipelineruns:    []*v1beta1.PipelineRun{failed(first), failed(retry1)},
+		expectedStatus:  corev1.ConditionUnknown,
+		expectedReason:  pipelineloopv1alpha1.PipelineLoopRunReasonRunning,
+		expectedCreated: [][2]string{{"1", "3"}},
+		expectedAttempts: map[string]pipelineloopv1alpha1.PipelineLoopPipelineRunStatus{
+			first.Name:  {Iteration: 1, Attempt: 1, Retried: true},
+			retry1.Name: {Iteration: 1, Attempt: 2, Retried: true},
+		},
+		expectedEvents: []string{"Normal Running Iterations completed: 0"},
+	}, {
+		name:           "Fail the run after the retries of the first PipelineRun are exhausted",
+		retries:        2,
+		pipelineruns:   []*v1beta1.PipelineRun{failed(first), failed(retry1), failed(retry2)},
+		expectedStatus: corev1.ConditionFalse,
+		expectedReason: pipelineloopv1alpha1.PipelineLoopRunReasonFailed,
+		expectedAttempts: map[string]pipelineloopv1alpha1.PipelineLoopPipelineRunStatus{
+			first.Name:  {Iteration: 1, Attempt: 1, Retried: true},
+			retry1.Name: {Iteration: 1, Attempt: 2, Retried: true},
+			retry2.Name: {Iteration: 1, Attempt: 3},
+		},
+		expectedEvents: []string{"Warning Failed PipelineRun " + retry2.Name + " has failed"},
+	}, {
+		name:            "Move on to the next iteration after the retry of the first PipelineRun succeeded",
+		retries:         2,
+		pipelineruns:    []*v1beta1.PipelineRun{failed(first), successful(retry1)},
+		expectedStatus:  corev1.ConditionUnknown,
+		expectedReason:  pipelineloopv1alpha1.PipelineLoopRunReasonRunning,
+		expectedCreated: [][2]string{{"2", ""}},
+		expectedAttempts: map[string]pipelineloopv1alpha1.PipelineLoopPipelineRunStatus{
+			first.Name:  {Iteration: 1, Attempt: 1, Retried: true},
+			retry1.Name: {Iteration: 1, Attempt: 2},
+		},
+		expectedEvents: []string{"Normal Running Iterations completed: 1"},
+	}, {
+		name:           "Don't retry without retries",
+		retries:        0,
+		pipelineruns:   []*v1beta1.PipelineRun{failed(first)},
+		expectedStatus: corev1.ConditionFalse,
+		expectedReason: pipelineloopv1alpha1.PipelineLoopRunReasonFailed,
+		expectedAttempts: map[string]pipelineloopv1alpha1.PipelineLoopPipelineRunStatus{
+			first.Name: {Iteration: 1, Attempt: 1},
+		},
+		expectedEvents: []string{"Warning Failed PipelineRun " + first.Name + " has failed"},
+	}}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			names.TestingSeed()
+
+			run := loopRunning(runPipelineLoop)
+			d := test.Data{
+				Runs:         []*v1alpha1.Run{run},
+				Pipelines:    []*v1beta1.Pipeline{aPipeline},
+				PipelineRuns: tc.pipelineruns,
+			}
+			testAssets, _ := getPipelineLoopController(t, d, []*pipelineloopv1alpha1.PipelineLoop{withRetries(aPipelineLoop, tc.retries)})
+			clients := testAssets.Clients
+
+			if err := testAssets.Controller.Reconciler.Reconcile(ctx, getRunName(run)); err != nil {
+				t.Fatalf("Error reconciling: %s", err)
+			}
+			reconciledRun, err := clients.Pipeline.TektonV1alpha1().Runs(run.Namespace).Get(ctx, run.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Error getting reconciled run from fake client: %s", err)
+			}
+			checkRunCondition(t, reconciledRun, tc.expectedStatus, tc.expectedReason)
+
+			// Verify that PipelineRuns were created for the expected iterations and attempts.
+			var created [][2]string
+			var createdNames []string
+			for _, a := range clients.Pipeline.Actions() {
+				if a.GetVerb() == "create" {
+					if pr, ok := a.(ktesting.CreateAction).GetObject().(*v1beta1.PipelineRun); ok {
+						created = append(created, [2]string{pr.Labels["custom.tekton.dev/pipelineLoopIteration"], pr.Labels["custom.tekton.dev/pipelineLoopAttempt"]})
+						createdNames = append(createdNames, pr.Name)
+					}
+				}
+			}
+			if d := cmp.Diff(tc.expectedCreated, created); d != "" {
+				t.Errorf("PipelineRuns were created for the wrong iterations or attempts. Diff %s", diff.PrintWantGot(d))
+			}
+
+			// Verify that the Run status keeps the history of the attempts.
+			status := &pipelineloopv1alpha1.PipelineLoopRunStatus{}
+			if err := reconciledRun.Status.DecodeExtraFields(status); err != nil {
+				t.Fatalf("DecodeExtraFields error: %v", err.Error())
+			}
+			if len(status.PipelineRuns) != len(tc.expectedAttempts)+len(createdNames) {
+				t.Errorf("Expected Run status to include %d PipelineRuns but found %d", len(tc.expectedAttempts)+len(createdNames), len(status.PipelineRuns))
+			}
+			for name, want := range tc.expectedAttempts {
+				got, ok := status.PipelineRuns[name]
+				if !ok {
+					t.Errorf("Expected Run status to include PipelineRun %s", name)
+					continue
+				}
+				if got.Iteration != want.Iteration || got.Attempt != want.Attempt || got.Retried != want.Retried {
+					t.Errorf("Run status for PipelineRun %s has iteration %d, attempt %d and retried %t instead of %d, %d and %t",
+						name, got.Iteration, got.Attempt, got.Retried, want.Iteration, want.Attempt, want.Retried)
+				}
+			}
+
+			if err := checkEvents(testAssets.Recorder, tc.name, tc.expectedEvents); err != nil {
+				t.Errorf(err.Error())
+			}
+		})
+	}
+}
+
+func TestReconcilePipelineLoopRunWithBreakCondition(t *testing.T) {
+	testcases := []struct {
+		name               string
+		breakCondition     string
+		pipelineruns       []*v1beta1.PipelineRun
+		expectedStatus     corev1.ConditionStatus
+		expectedReason     pipelineloopv1alpha1.PipelineLoopRunReason
+		expectedIterations []string
+		expectedResults    []runv1alpha1.RunResult
+		expectedEvents     []string
+	}{{
+		name:           "Stop the loop when the results of the first PipelineRun meet the break condition",
+		breakCondition: "results.ready == 'true'",
+		pipelineruns:   []*v1beta1.PipelineRun{withResults(successful(expectedPipelineRunIteration1), map[string]string{"ready": "true"})},
+		expectedStatus: corev1.ConditionTrue,
+		expectedReason: pipelineloopv1alpha1.PipelineLoopRunReasonSucceeded,
+		expectedResults: []runv1alpha1.RunResult{{
+			Name:  "condition",
+			Value: "pass",
+		}, {
+			Name:  "ready",
+			Value: `["true",null]`,
+		}},
+		expectedEvents: []string{"Normal Succeeded PipelineRuns completed successfully with the conditions are met"},
+	}, {
+		name:               "Continue the loop when the results of the first PipelineRun don't meet the break condition",
+		breakCondition:     "results.ready == 'true'",
+		pipelineruns:       []*v1beta1.PipelineRun{withResults(successful(expectedPipelineRunIteration1), map[string]string{"ready": "false"})},
+		expectedStatus:     corev1.ConditionUnknown,
+		expectedReason:     pipelineloopv1alpha1.PipelineLoopRunReasonRunning,
+		expectedIterations: []string{"2"},
+		expectedEvents:     []string{"Normal Running Iterations completed: 1"},
+	}, {
+		name:               "Continue the loop when the first PipelineRun doesn't produce the result of the break condition",
+		breakCondition:     "results.ready == 'true'",
+		pipelineruns:       []*v1beta1.PipelineRun{successful(expectedPipelineRunIteration1)},
+		expectedStatus:     corev1.ConditionUnknown,
+		expectedReason:     pipelineloopv1alpha1.PipelineLoopRunReasonRunning,
+		expectedIterations: []string{"2"},
+		expectedEvents:     []string{"Normal Running Iterations completed: 1"},
+	}, {
+		name:           "Succeed with a failed condition when no PipelineRun meets the break condition",
+		breakCondition: "has(results.ready) && results.ready == 'true'",
+		pipelineruns: []*v1beta1.PipelineRun{
+			withResults(successful(expectedPipelineRunIteration1), map[string]string{"ready": "false"}),
+			successful(expectedPipelineRunIteration2),
+		},
+		expectedStatus: corev1.ConditionTrue,
+		expectedReason: pipelineloopv1alpha1.PipelineLoopRunReasonSucceeded,
+		expectedResults: []runv1alpha1.RunResult{{
+			Name:  "condition",
+			Value: "fail",
+		}, {
+			Name:  "ready",
+			Value: `["false",null]`,
+		}},
+		expectedEvents: []string{"Normal Succeeded All PipelineRuns completed successfully"},
+	}, {
+		name:           "Fail the run when the break condition isn't a bool",
+		breakCondition: "results.ready",
+		expectedStatus: corev1.ConditionFalse,
+		expectedReason: pipelineloopv1alpha1.PipelineLoopRunReasonFailedValidation,
+		expectedEvents: []string{"Warning Failed PipelineLoop foo/a-pipelineloop can't be Run; it has an invalid spec: " +
+			"invalid value: expression \"results.ready\" must evaluate to a bool: spec.breakCondition"},
+	}}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			names.TestingSeed()
+
+			run := loopRunning(runPipelineLoop)
+			d := test.Data{
+				Runs:         []*v1alpha1.Run{run},
+				Pipelines:    []*v1beta1.Pipeline{aPipeline},
+				PipelineRuns: tc.pipelineruns,
+			}
+			testAssets, _ := getPipelineLoopController(t, d, []*pipelineloopv1alpha1.PipelineLoop{withBreakCondition(aPipelineLoop, tc.breakCondition)})
+			clients := testAssets.Clients
+
+			if err := testAssets.Controller.Reconciler.Reconcile(ctx, getRunName(run)); err != nil {
+				t.Fatalf("Error reconciling: %s", err)
+			}
+			reconciledRun, err := clients.Pipeline.TektonV1alpha1().Runs(run.Namespace).Get(ctx, run.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Error getting reconciled run from fake client: %s", err)
+			}
+			checkRunCondition(t, reconciledRun, tc.expectedStatus, tc.expectedReason)
+
+			// Verify that PipelineRuns were created for the expected iterations.
+			var createdIterations []string
+			for _, a := range clients.Pipeline.Actions() {
+				if a.GetVerb() == "create" {
+					if pr, ok := a.(ktesting.CreateAction).GetObject().(*v1beta1.PipelineRun); ok {
+						createdIterations = append(createdIterations, pr.Labels["custom.tekton.dev/pipelineLoopIteration"])
+					}
+				}
+			}
+			if d := cmp.Diff(tc.expectedIterations, createdIterations); d != "" {
+				t.Errorf("PipelineRuns were created for the wrong iterations. Diff %s", diff.PrintWantGot(d))
+			}
+
+			if d := cmp.Diff(tc.expectedResults, reconciledRun.Status.Results); d != "" {
+				t.Errorf("Run results are wrong. Diff %s", diff.PrintWantGot(d))
+			}
+
+			if err := checkEvents(testAssets.Recorder, tc.name, tc.expectedEvents); err != nil {
+				t.Errorf(err.Error())
+			}
+		})
+	}
+}
+
+func TestReconcilePipelineLoopRunWithResultParams(t *testing.T) {
+	resultParam := pipelineloopv1alpha1.PipelineLoopResultParam{
+		Result: "next",
+		Param:  "additional-parameter",
+		Seed:   "first",
+	}
+	stringParam := func(name, value string) v1beta1.Param {
+		return v1beta1.Param{Name: name, Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: value}}
+	}
+
+	testcases := []struct {
+		name           string
+		run            *v1alpha1.Run
+		pipelineruns   []*v1beta1.PipelineRun
+		expectedStatus corev1.ConditionStatus
+		expectedReason pipelineloopv1alpha1.PipelineLoopRunReason
+		expectedParams [][]v1beta1.Param
+		expectedEvents []string
+	}{{
+		name:           "Pass the seed value to the first iteration",
+		run:            runPipelineLoop,
+		expectedStatus: corev1.ConditionUnknown,
+		expectedReason: pipelineloopv1alpha1.PipelineLoopRunReasonRunning,
+		expectedParams: [][]v1beta1.Param{{stringParam("current-item", "item1"), stringParam("additional-parameter", "first")}},
+		expectedEvents: []string{"Normal Started", "Normal Running Iterations completed: 0"},
+	}, {
+		name:           "Pass the result of the first iteration to the second iteration",
+		run:            loopRunning(runPipelineLoop),
+		pipelineruns:   []*v1beta1.PipelineRun{withResults(successful(expectedPipelineRunIteration1), map[string]string{"next": "second"})},
+		expectedStatus: corev1.ConditionUnknown,
+		expectedReason: pipelineloopv1alpha1.PipelineLoopRunReasonRunning,
+		expectedParams: [][]v1beta1.Param{{stringParam("current-item", "item2"), stringParam("additional-parameter", "second")}},
+		expectedEvents: []string{"Normal Running Iterations completed: 1"},
+	}, {
+		name:           "Fail the run when the first iteration doesn't produce the result",
+		run:            loopRunning(runPipelineLoop),
+		pipelineruns:   []*v1beta1.PipelineRun{successful(expectedPipelineRunIteration1)},
+		expectedStatus: corev1.ConditionFalse,
+		expectedReason: pipelineloopv1alpha1.PipelineLoopRunReasonFailed,
+		expectedEvents: []string{"Warning Failed Cannot pass results to iteration 2: PipelineRun " + expectedPipelineRunIteration1.Name +
+			" of iteration 1 didn't produce the result \"next\""},
+	}}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			names.TestingSeed()
+
+			d := test.Data{
+				Runs:         []*v1alpha1.Run{tc.run},
+				Pipelines:    []*v1beta1.Pipeline{aPipeline},
+				PipelineRuns: tc.pipelineruns,
+			}
+			testAssets, _ := getPipelineLoopController(t, d, []*pipelineloopv1alpha1.PipelineLoop{withResultParams(aPipelineLoop, resultParam)})
+			clients := testAssets.Clients
+
+			if err := testAssets.Controller.Reconciler.Reconcile(ctx, getRunName(tc.run)); err != nil {
+				t.Fatalf("Error reconciling: %s", err)
+			}
+			reconciledRun, err := clients.Pipeline.TektonV1alpha1().Runs(tc.run.Namespace).Get(ctx, tc.run.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Error getting reconciled run from fake client: %s", err)
+			}
+			checkRunCondition(t, reconciledRun, tc.expectedStatus, tc.expectedReason)
+
+			// Verify that the PipelineRuns were created with the results of the previous iteration.
+			var createdParams [][]v1beta1.Param
+			for _, a := range clients.Pipeline.Actions() {
+				if a.GetVerb() == "create" {
+					if pr, ok := a.(ktesting.CreateAction).GetObject().(*v1beta1.PipelineRun); ok {
+						createdParams = append(createdParams, pr.Spec.Params)
+					}
+				}
+			}
+			if d := cmp.Diff(tc.expectedParams, createdParams); d != "" {
+				t.Errorf("PipelineRuns were created with the wrong params. Diff %s", diff.PrintWantGot(d))
+			}
+
+			if err := checkEvents(testAssets.Recorder, tc.name, tc.expectedEvents); err != nil {
+				t.Errorf(err.Error())
+			}
+		})
+	}
+}
+
+func TestReconcilePipelineLoopRunResults(t *testing.T) {
+	testcases := []struct {
+		name            string
+		pipelineruns    []*v1beta1.PipelineRun
+		expectedStatus  corev1.ConditionStatus
+		expectedReason  pipelineloopv1alpha1.PipelineLoopRunReason
+		expectedResults []runv1alpha1.RunResult
+	}{{
+		name: "Aggregate the results of all iterations",
+		pipelineruns: []*v1beta1.PipelineRun{
+			withResults(successful(expectedPipelineRunIteration1), map[string]string{"digest": "sha256:1", "version": "1"}),
+			withResults(successful(expectedPipelineRunIteration2), map[string]string{"digest": "sha256:2"}),
+		},
+		expectedStatus: corev1.ConditionTrue,
+		expectedReason: pipelineloopv1alpha1.PipelineLoopRunReasonSucceeded,
+		expectedResults: []runv1alpha1.RunResult{{
+			Name:  "condition",
+			Value: "fail",
+		}, {
+			Name:  "digest",
+			Value: `["sha256:1","sha256:2"]`,
+		}, {
+			Name:  "version",
+			Value: `["1",null]`,
+		}},
+	}, {
+		name: "Aggregate the results of the iterations that succeeded when an iteration failed",
+		pipelineruns: []*v1beta1.PipelineRun{
+			withResults(successful(expectedPipelineRunIteration1), map[string]string{"digest": "sha256:1"}),
+			withResults(failed(expectedPipelineRunIteration2), map[string]string{"digest": "sha256:2"}),
+		},
+		expectedStatus: corev1.ConditionFalse,
+		expectedReason: pipelineloopv1alpha1.PipelineLoopRunReasonFailed,
+		expectedResults: []runv1alpha1.RunResult{{
+			Name:  "digest",
+			Value: `["sha256:1",null]`,
+		}},
+	}}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			names.TestingSeed()
+
+			run := loopRunning(runPipelineLoop)
+			d := test.Data{
+				Runs:         []*v1alpha1.Run{run},
+				Pipelines:    []*v1beta1.Pipeline{aPipeline},
+				PipelineRuns: tc.pipelineruns,
+			}
+			testAssets, _ := getPipelineLoopController(t, d, []*pipelineloopv1alpha1.PipelineLoop{aPipelineLoop})
+			clients := testAssets.Clients
+
+			if err := testAssets.Controller.Reconciler.Reconcile(ctx, getRunName(run)); err != nil {
+				t.Fatalf("Error reconciling: %s", err)
+			}
+			reconciledRun, err := clients.Pipeline.TektonV1alpha1().Runs(run.Namespace).Get(ctx, run.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Error getting reconciled run from fake client: %s", err)
+			}
+			checkRunCondition(t, reconciledRun, tc.expectedStatus, tc.expectedReason)
+
+			if d := cmp.Diff(tc.expectedResults, reconciledRun.Status.Results); d != "" {
+				t.Errorf("Run results are wrong. Diff %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestReconcilePipelineLoopRunWithWorkspaces(t *testing.T) {
+	run := loopRunning(runPipelineLoop)
+	run.Spec.ServiceAccountName = "deployer"
+	run.Spec.PodTemplate = &v1alpha1.PodTemplate{
+		NodeSelector: map[string]string{"workload": "deploy"},
+	}
+	run.Spec.Workspaces = []v1beta1.WorkspaceBinding{{
+		Name:                  "source",
+		PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "source-pvc"},
+		SubPath:               "src",
+	}, {
+		Name:                  "scratch",
+		PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "scratch-pvc"},
+		SubPath:               "loop",
+	}}
+	isolated := aPipelineLoop.DeepCopy()
+	isolated.Spec.IsolatedWorkspaces = []string{"scratch"}
+	unbound := aPipelineLoop.DeepCopy()
+	unbound.Spec.IsolatedWorkspaces = []string{"cache"}
+
+	testcases := []struct {
+		name               string
+		pipelineLoop       *pipelineloopv1alpha1.PipelineLoop
+		pipelineruns       []*v1beta1.PipelineRun
+		expectedStatus     corev1.ConditionStatus
+		expectedReason     pipelineloopv1alpha1.PipelineLoopRunReason
+		expectedWorkspaces [][]v1beta1.WorkspaceBinding
+		expectedEvents     []string
+	}{{
+		name:           "Share the workspaces of the Run with the PipelineRun",
+		pipelineLoop:   aPipelineLoop,
+		expectedStatus: corev1.ConditionUnknown,
+		expectedReason: pipelineloopv1alpha1.PipelineLoopRunReasonRunning,
+		expectedWorkspaces: [][]v1beta1.WorkspaceBinding{
+			run.Spec.Workspaces,
+		},
+		expectedEvents: []string{"Normal Running Iterations completed: 0"},
+	}, {
+		name:           "Isolate a workspace with a subPath for the second iteration",
+		pipelineLoop:   isolated,
+		pipelineruns:   []*v1beta1.PipelineRun{successful(expectedPipelineRunIteration1)},
+		expectedStatus: corev1.ConditionUnknown,
+		expectedReason: pipelineloopv1alpha1.PipelineLoopRunReasonRunning,
+		expectedWorkspaces: [][]v1beta1.WorkspaceBinding{{
+			run.Spec.Workspaces[0],
+			{
+				Name:                  "scratch",
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "scratch-pvc"},
+				SubPath:               "loop/iteration-2",
+			},
+		}},
+		expectedEvents: []string{"Normal Running Iterations completed: 1"},
+	}, {
+		name:           "Fail the run when an isolated workspace isn't bound",
+		pipelineLoop:   unbound,
+		expectedStatus: corev1.ConditionFalse,
+		expectedReason: pipelineloopv1alpha1.PipelineLoopRunReasonFailedValidation,
+		expectedEvents: []string{"Warning Failed Isolated workspace \"cache\" is not bound by Run foo/run-pipelineloop"},
+	}}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			names.TestingSeed()
+
+			d := test.Data{
+				Runs:         []*v1alpha1.Run{run},
+				Pipelines:    []*v1beta1.Pipeline{aPipeline},
+				PipelineRuns: tc.pipelineruns,
+			}
+			testAssets, _ := getPipelineLoopController(t, d, []*pipelineloopv1alpha1.PipelineLoop{tc.pipelineLoop})
+			clients := testAssets.Clients
+
+			if err := testAssets.Controller.Reconciler.Reconcile(ctx, getRunName(run)); err != nil {
+				t.Fatalf("Error reconciling: %s", err)
+			}
+			reconciledRun, err := clients.Pipeline.TektonV1alpha1().Runs(run.Namespace).Get(ctx, run.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Error getting reconciled run from fake client: %s", err)
+			}
+			checkRunCondition(t, reconciledRun, tc.expectedStatus, tc.expectedReason)
+
+			// Verify that the PipelineRuns were created with the service account, pod template and workspaces of the Run.
+			var createdWorkspaces [][]v1beta1.WorkspaceBinding
+			for _, a := range clients.Pipeline.Actions() {
+				if a.GetVerb() == "create" {
+					if pr, ok := a.(ktesting.CreateAction).GetObject().(*v1beta1.PipelineRun); ok {
+						if pr.Spec.ServiceAccountName != run.Spec.ServiceAccountName {
+							t.Errorf("Expected PipelineRun %s to use service account %s but it uses %s", pr.Name, run.Spec.ServiceAccountName, pr.Spec.ServiceAccountName)
+						}
+						if d := cmp.Diff(run.Spec.PodTemplate, pr.Spec.PodTemplate); d != "" {
+							t.Errorf("PipelineRun %s has the wrong pod template. Diff %s", pr.Name, diff.PrintWantGot(d))
+						}
+						createdWorkspaces = append(createdWorkspaces, pr.Spec.Workspaces)
+					}
+				}
+			}
+			if d := cmp.Diff(tc.expectedWorkspaces, createdWorkspaces); d != "" {
+				t.Errorf("PipelineRuns were created with the wrong workspaces. Diff %s", diff.PrintWantGot(d))
+			}
+
+			if err := checkEvents(testAssets.Recorder, tc.name, tc.expectedEvents); err != nil {
+				t.Errorf(err.Error())
+			}
+		})
+	}
+}
+
+func TestReconcilePipelineLoopRunResolvesPipeline(t *testing.T) {
+	testcases := []struct {
+		name           string
+		pipelineLoop   *pipelineloopv1alpha1.PipelineLoop
+		pipelines      []*v1beta1.Pipeline
+		expectedStatus corev1.ConditionStatus
+		expectedReason pipelineloopv1alpha1.PipelineLoopRunReason
+		expectedEvents []string
+	}{{
+		name:           "pipeline",
+		pipelineLoop:   aPipelineLoop,
+		pipelines:      []*v1beta1.Pipeline{aPipeline},
+		expectedStatus: corev1.ConditionUnknown,
+		expectedReason: pipelineloopv1alpha1.PipelineLoopRunReasonRunning,
+		expectedEvents: []string{"Normal Running Iterations completed: 0"},
+	}, {
+		name: "inline pipeline",
+		pipelineLoop: &pipelineloopv1alpha1.PipelineLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "a-pipelineloop", Namespace: "foo"},
+			Spec: pipelineloopv1alpha1.PipelineLoopSpec{
+				PipelineSpec: &aPipeline.Spec,
+				IterateParam: "current-item",
+			},
+		},
+		expectedStatus: corev1.ConditionUnknown,
+		expectedReason: pipelineloopv1alpha1.PipelineLoopRunReasonRunning,
+		expectedEvents: []string{"Normal Running Iterations completed: 0"},
+	}, {
+		name:           "missing pipeline",
+		pipelineLoop:   aPipelineLoop,
+		expectedStatus: corev1.ConditionFalse,
+		expectedReason: pipelineloopv1alpha1.PipelineLoopRunReasonCouldntGetPipeline,
+		expectedEvents: []string{"Warning Failed Error retrieving Pipeline for Run foo/run-pipelineloop: pipelines.tekton.dev \"a-pipeline\" not found"},
+	}, {
+		name: "bundle without the service account of the Run",
+		pipelineLoop: &pipelineloopv1alpha1.PipelineLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "a-pipelineloop", Namespace: "foo"},
+			Spec: pipelineloopv1alpha1.PipelineLoopSpec{
+				PipelineRef:  &v1beta1.PipelineRef{Name: "a-bundled-pipeline", Bundle: "registry.example.com/pipelineloop/bundle:latest"},
+				IterateParam: "current-item",
+			},
+		},
+		expectedStatus: corev1.ConditionFalse,
+		expectedReason: pipelineloopv1alpha1.PipelineLoopRunReasonCouldntGetPipeline,
+		expectedEvents: []string{"Warning Failed Error retrieving Pipeline for Run foo/run-pipelineloop: failed to get keychain: serviceaccounts \"default\" not found"},
+	}}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			names.TestingSeed()
+
+			run := loopRunning(runPipelineLoop)
+			d := test.Data{
+				Runs:      []*v1alpha1.Run{run},
+				Pipelines: tc.pipelines,
+			}
+			testAssets, _ := getPipelineLoopController(t, d, []*pipelineloopv1alpha1.PipelineLoop{tc.pipelineLoop})
+			clients := testAssets.Clients
+
+			if err := testAssets.Controller.Reconciler.Reconcile(ctx, getRunName(run)); err != nil {
+				t.Fatalf("Error reconciling: %s", err)
+			}
+			reconciledRun, err := clients.Pipeline.TektonV1alpha1().Runs(run.Namespace).Get(ctx, run.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Error getting reconciled run from fake client: %s", err)
+			}
+			checkRunCondition(t, reconciledRun, tc.expectedStatus, tc.expectedReason)
+
+			if tc.expectedStatus != corev1.ConditionFalse {
+				// Verify that the Run status contains the spec of the pipeline.
+				status := &pipelineloopv1alpha1.PipelineLoopRunStatus{}
+				if err := reconciledRun.Status.DecodeExtraFields(status); err != nil {
+					t.Fatalf("DecodeExtraFields error: %v", err.Error())
+				}
+				if d := cmp.Diff(&aPipeline.Spec, status.PipelineSpec); d != "" {
+					t.Errorf("Run status has incorrect pipeline spec. Diff %s", diff.PrintWantGot(d))
+				}
+
+				// Verify that the PipelineRun embeds the spec of the pipeline rather than referencing it.
+				createdPipelineRuns := getCreatedPipelineRuns(clients)
+				if len(createdPipelineRuns) != 1 {
+					t.Fatalf("Expected 1 PipelineRun to be created but found %d", len(createdPipelineRuns))
+				}
+				if createdPipelineRuns[0].Spec.PipelineRef != nil {
+					t.Errorf("Expected PipelineRun to have no pipelineRef but it has %v", createdPipelineRuns[0].Spec.PipelineRef)
+				}
+				if d := cmp.Diff(&aPipeline.Spec, createdPipelineRuns[0].Spec.PipelineSpec); d != "" {
+					t.Errorf("PipelineRun has incorrect pipeline spec. Diff %s", diff.PrintWantGot(d))
+				}
+			}
+
+			if err := checkEvents(testAssets.Recorder, tc.name, tc.expectedEvents); err != nil {
+				t.Errorf(err.Error())
+			}
+		})
+	}
+}
+
+func TestReconcilePipelineLoopRunWithBundle(t *testing.T) {
+	ctx := context.Background()
+	names.TestingSeed()
+
+	// Set up a fake registry to push the bundle to.
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle := u.Host + "/pipelineloop/bundle:latest"
+	pushPipeline := func(image string) {
+		pipeline := aPipeline.DeepCopy()
+		pipeline.TypeMeta = metav1.TypeMeta{APIVersion: "tekton.dev/v1beta1", Kind: "Pipeline"}
+		pipeline.ObjectMeta = metav1.ObjectMeta{Name: "a-bundled-pipeline"}
+		pipeline.Spec.Tasks[0].TaskSpec.Steps[0].Image = image
+		if _, err := ptest.CreateImage(bundle, pipeline); err != nil {
+			t.Fatalf("Error pushing bundle: %s", err)
+		}
+	}
+	pushPipeline("bar")
+
+	pipelineLoop := &pipelineloopv1alpha1.PipelineLoop{
+		ObjectMeta: metav1.ObjectMeta{Name: "a-pipelineloop", Namespace: "foo"},
+		Spec: pipelineloopv1alpha1.PipelineLoopSpec{
+			PipelineRef:  &v1beta1.PipelineRef{Name: "a-bundled-pipeline", Bundle: bundle},
+			IterateParam: "current-item",
+		},
+	}
+	d := test.Data{
+		Runs: []*v1alpha1.Run{runPipelineLoop},
+		// The bundle is pulled with the credentials of the Run's service account.
+		ServiceAccounts: []*corev1.ServiceAccount{{
+			ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "foo"},
+		}},
+	}
+	testAssets, _ := getPipelineLoopController(t, d, []*pipelineloopv1alpha1.PipelineLoop{pipelineLoop})
+	clients := testAssets.Clients
+
+	// The first reconcile resolves the bundle and creates the first PipelineRun.
+	if err := testAssets.Controller.Reconciler.Reconcile(ctx, getRunName(runPipelineLoop)); err != nil {
+		t.Fatalf("Error reconciling: %s", err)
+	}
+	createdPipelineRuns := getCreatedPipelineRuns(clients)
+	if len(createdPipelineRuns) != 1 {
+		t.Fatalf("Expected 1 PipelineRun to be created but found %d", len(createdPipelineRuns))
+	}
+	firstPipelineRun := createdPipelineRuns[0]
+	if firstPipelineRun.Spec.PipelineRef != nil || firstPipelineRun.Spec.PipelineSpec == nil {
+		t.Fatalf("Expected PipelineRun to embed the pipeline from the bundle but it has pipelineRef %v", firstPipelineRun.Spec.PipelineRef)
+	}
+	if image := firstPipelineRun.Spec.PipelineSpec.Tasks[0].TaskSpec.Steps[0].Image; image != "bar" {
+		t.Errorf("Expected PipelineRun to run the pipeline from the bundle but its step has image %s", image)
+	}
+
+	// Move the bundle tag to a different pipeline and let the first PipelineRun complete.
+	pushPipeline("changed")
+	pr, err := clients.Pipeline.TektonV1beta1().PipelineRuns(firstPipelineRun.Namespace).Get(ctx, firstPipelineRun.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Error getting PipelineRun: %s", err)
+	}
+	if _, err := clients.Pipeline.TektonV1beta1().PipelineRuns(pr.Namespace).UpdateStatus(ctx, successful(pr), metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Error updating PipelineRun status: %s", err)
+	}
+
+	// The second reconcile creates the next PipelineRun from the pipeline that was resolved for the Run.
+	if err := testAssets.Controller.Reconciler.Reconcile(ctx, getRunName(runPipelineLoop)); err != nil {
+		t.Fatalf("Error reconciling: %s", err)
+	}
+	createdPipelineRuns = getCreatedPipelineRuns(clients)
+	if len(createdPipelineRuns) != 2 {
+		t.Fatalf("Expected 2 PipelineRuns to be created but found %d", len(createdPipelineRuns))
+	}
+	for _, pr := range createdPipelineRuns {
+		if d := cmp.Diff(firstPipelineRun.Spec.PipelineSpec, pr.Spec.PipelineSpec); d != "" {
+			t.Errorf("PipelineRun %s does not run the pipeline that was resolved for the Run. Diff %s", pr.Name, diff.PrintWantGot(d))
+		}
+	}
+}
+
+func TestReconcilePipelineLoopRunWithPrivateBundle(t *testing.T) {
+	ctx := context.Background()
+	names.TestingSeed()
+
+	// Set up a fake registry that only serves the bundle to the credentials of the Run's service account once the
+	// bundle has been pushed.
+	requireAuth := false
+	r := registry.New()
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if user, password, ok := req.BasicAuth(); requireAuth && (!ok || user != "deployer" || password != "secret") {
+			w.Header().Set("WWW-Authenticate", `Basic realm="pipelineloop"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		r.ServeHTTP(w, req)
+	}))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle := u.Host + "/pipelineloop/bundle:latest"
+	pipeline := aPipeline.DeepCopy()
+	pipeline.TypeMeta = metav1.TypeMeta{APIVersion: "tekton.dev/v1beta1", Kind: "Pipeline"}
+	pipeline.ObjectMeta = metav1.ObjectMeta{Name: "a-bundled-pipeline"}
+	if _, err := ptest.CreateImage(bundle, pipeline); err != nil {
+		t.Fatalf("Error pushing bundle: %s", err)
+	}
+	requireAuth = true
+
+	pipelineLoop := &pipelineloopv1alpha1.PipelineLoop{
+		ObjectMeta: metav1.ObjectMeta{Name: "a-pipelineloop", Namespace: "foo"},
+		Spec: pipelineloopv1alpha1.PipelineLoopSpec{
+			PipelineRef:  &v1beta1.PipelineRef{Name: "a-bundled-pipeline", Bundle: bundle},
+			IterateParam: "current-item",
+		},
+	}
+	run := runPipelineLoop.DeepCopy()
+	run.Spec.ServiceAccountName = "deployer"
+	d := test.Data{
+		Runs: []*v1alpha1.Run{run},
+		ServiceAccounts: []*corev1.ServiceAccount{{
+			ObjectMeta:       metav1.ObjectMeta{Name: "deployer", Namespace: "foo"},
+			ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry-credentials"}},
+		}},
+	}
+	testAssets, _ := getPipelineLoopController(t, d, []*pipelineloopv1alpha1.PipelineLoop{pipelineLoop})
+	clients := testAssets.Clients
+	if _, err := clients.Kube.CoreV1().Secrets("foo").Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "registry-credentials", Namespace: "foo"},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{
+			corev1.DockerConfigJsonKey: []byte(fmt.Sprintf(`{"auths":{%q:{"username":"deployer","password":"secret"}}}`, u.Host)),
+		},
+	}, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating secret: %s", err)
+	}
+
+	if err := testAssets.Controller.Reconciler.Reconcile(ctx, getRunName(run)); err != nil {
+		t.Fatalf("Error reconciling: %s", err)
+	}
+	reconciledRun, err := clients.Pipeline.TektonV1alpha1().Runs(run.Namespace).Get(ctx, run.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Error getting reconciled run from fake client: %s", err)
+	}
+	checkRunCondition(t, reconciledRun, corev1.ConditionUnknown, pipelineloopv1alpha1.PipelineLoopRunReasonRunning)
+	if createdPipelineRuns := getCreatedPipelineRuns(clients); len(createdPipelineRuns) != 1 {
+		t.Fatalf("Expected 1 PipelineRun to be created but found %d", len(createdPipelineRuns))
+	}
+}
+
+func TestReconcilePipelineLoopRunPrunesSucceeded(t *testing.T) {
+	pipelineLoop := aPipelineLoop.DeepCopy()
+	pipelineLoop.Spec.PruneSucceeded = true
+
+	// The status of the first iteration once it has been pruned.
+	summary1 := withResults(successful(expectedPipelineRunIteration1), map[string]string{"digest": "sha256:1"})
+	// The first iteration as it is listed before it is pruned.
+	iteration1 := summary1.DeepCopy()
+	iteration1.Status.TaskRuns = map[string]*v1beta1.PipelineRunTaskRunStatus{
+		"pr-loop-task": {PipelineTaskName: "loop-task"},
+	}
+	prunedRun := loopRunning(runPipelineLoop)
+	if err := prunedRun.Status.EncodeExtraFields(&pipelineloopv1alpha1.PipelineLoopRunStatus{
+		PipelineRuns: map[string]*pipelineloopv1alpha1.PipelineLoopPipelineRunStatus{
+			summary1.Name: {Iteration: 1, Attempt: 1, Pruned: true, Status: &summary1.Status},
+		},
+	}); err != nil {
+		t.Fatalf("EncodeExtraFields error: %v", err)
+	}
+	// A Run that completed after recording the summary of its last iteration.
+	summary2 := withResults(successful(expectedPipelineRunIteration2), map[string]string{"digest": "sha256:2"})
+	completedRun := loopRunning(runPipelineLoop)
+	completedRun.Status.MarkRunSucceeded(pipelineloopv1alpha1.PipelineLoopRunReasonSucceeded.String(), "All PipelineRuns completed successfully")
+	if err := completedRun.Status.EncodeExtraFields(&pipelineloopv1alpha1.PipelineLoopRunStatus{
+		PipelineLoopSpec: &pipelineLoop.Spec,
+		PipelineRuns: map[string]*pipelineloopv1alpha1.PipelineLoopPipelineRunStatus{
+			summary1.Name: {Iteration: 1, Attempt: 1, Pruned: true, Status: &summary1.Status},
+			summary2.Name: {Iteration: 2, Attempt: 1, Pruned: true, Status: &summary2.Status},
+		},
+	}); err != nil {
+		t.Fatalf("EncodeExtraFields error: %v", err)
+	}
+
+	testcases := []struct {
+		name               string
+		run                *v1alpha1.Run
+		pipelineruns       []*v1beta1.PipelineRun
+		expectedStatus     corev1.ConditionStatus
+		expectedReason     pipelineloopv1alpha1.PipelineLoopRunReason
+		expectedDeleted    []string
+		expectedIterations []string
+		expectedRunStatus  map[string]pipelineloopv1alpha1.PipelineLoopPipelineRunStatus
+		expectedResults    []runv1alpha1.RunResult
+	}{{
+		name:               "Record the summary of an iteration that succeeded and start the next one",
+		run:                loopRunning(runPipelineLoop),
+		pipelineruns:       []*v1beta1.PipelineRun{iteration1},
+		expectedStatus:     corev1.ConditionUnknown,
+		expectedReason:     pipelineloopv1alpha1.PipelineLoopRunReasonRunning,
+		expectedIterations: []string{"2"},
+		expectedRunStatus: map[string]pipelineloopv1alpha1.PipelineLoopPipelineRunStatus{
+			iteration1.Name:                    {Iteration: 1, Pruned: true, Status: &summary1.Status},
+			expectedPipelineRunIteration2.Name: {Iteration: 2, Status: &v1beta1.PipelineRunStatus{}},
+		},
+	}, {
+		name:               "Prune an iteration once its summary is recorded",
+		run:                prunedRun,
+		pipelineruns:       []*v1beta1.PipelineRun{iteration1},
+		expectedStatus:     corev1.ConditionUnknown,
+		expectedReason:     pipelineloopv1alpha1.PipelineLoopRunReasonRunning,
+		expectedDeleted:    []string{iteration1.Name},
+		expectedIterations: []string{"2"},
+		expectedRunStatus: map[string]pipelineloopv1alpha1.PipelineLoopPipelineRunStatus{
+			iteration1.Name:                    {Iteration: 1, Pruned: true, Status: &summary1.Status},
+			expectedPipelineRunIteration2.Name: {Iteration: 2, Status: &v1beta1.PipelineRunStatus{}},
+		},
+	}, {
+		name:               "Don't repeat a pruned iteration",
+		run:                prunedRun,
+		expectedStatus:     corev1.ConditionUnknown,
+		expectedReason:     pipelineloopv1alpha1.PipelineLoopRunReasonRunning,
+		expectedIterations: []string{"2"},
+		expectedRunStatus: map[string]pipelineloopv1alpha1.PipelineLoopPipelineRunStatus{
+			summary1.Name:                      {Iteration: 1, Pruned: true, Status: &summary1.Status},
+			expectedPipelineRunIteration2.Name: {Iteration: 2, Status: &v1beta1.PipelineRunStatus{}},
+		},
+	}, {
+		name:           "Aggregate the results of pruned iterations",
+		run:            prunedRun,
+		pipelineruns:   []*v1beta1.PipelineRun{summary2},
+		expectedStatus: corev1.ConditionTrue,
+		expectedReason: pipelineloopv1alpha1.PipelineLoopRunReasonSucceeded,
+		expectedRunStatus: map[string]pipelineloopv1alpha1.PipelineLoopPipelineRunStatus{
+			summary1.Name: {Iteration: 1, Pruned: true, Status: &summary1.Status},
+			summary2.Name: {Iteration: 2, Pruned: true, Status: &summary2.Status},
+		},
+		expectedResults: []runv1alpha1.RunResult{{
+			Name:  "condition",
+			Value: "fail",
+		}, {
+			Name:  "digest",
+			Value: `["sha256:1","sha256:2"]`,
+		}},
+	}, {
+		name:            "Prune the last iteration once the Run is done",
+		run:             completedRun,
+		pipelineruns:    []*v1beta1.PipelineRun{summary2},
+		expectedStatus:  corev1.ConditionTrue,
+		expectedReason:  pipelineloopv1alpha1.PipelineLoopRunReasonSucceeded,
+		expectedDeleted: []string{summary2.Name},
+		expectedRunStatus: map[string]pipelineloopv1alpha1.PipelineLoopPipelineRunStatus{
+			summary1.Name: {Iteration: 1, Pruned: true, Status: &summary1.Status},
+			summary2.Name: {Iteration: 2, Pruned: true, Status: &summary2.Status},
+		},
+	}, {
+		name:           "Keep the PipelineRuns that failed",
+		run:            loopRunning(runPipelineLoop),
+		pipelineruns:   []*v1beta1.PipelineRun{failed(expectedPipelineRunIteration1)},
+		expectedStatus: corev1.ConditionFalse,
+		expectedReason: pipelineloopv1alpha1.PipelineLoopRunReasonFailed,
+		expectedRunStatus: map[string]pipelineloopv1alpha1.PipelineLoopPipelineRunStatus{
+			expectedPipelineRunIteration1.Name: {Iteration: 1, Status: &failed(expectedPipelineRunIteration1).Status},
+		},
+	}}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			names.TestingSeed()
+
+			d := test.Data{
+				Runs:         []*v1alpha1.Run{tc.run},
+				Pipelines:    []*v1beta1.Pipeline{aPipeline},
+				PipelineRuns: tc.pipelineruns,
+			}
+			testAssets, _ := getPipelineLoopController(t, d, []*pipelineloopv1alpha1.PipelineLoop{pipelineLoop})
+			clients := testAssets.Clients
+
+			if err := testAssets.Controller.Reconciler.Reconcile(ctx, getRunName(tc.run)); err != nil {
+				t.Fatalf("Error reconciling: %s", err)
+			}
+			reconciledRun, err := clients.Pipeline.TektonV1alpha1().Runs(tc.run.Namespace).Get(ctx, tc.run.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Error getting reconciled run from fake client: %s", err)
+			}
+			checkRunCondition(t, reconciledRun, tc.expectedStatus, tc.expectedReason)
+
+			var deleted []string
+			for _, a := range clients.Pipeline.Actions() {
+				if a.GetVerb() == "delete" && a.GetResource().Resource == "pipelineruns" {
+					deleted = append(deleted, a.(ktesting.DeleteAction).GetName())
+				}
+			}
+			if d := cmp.Diff(tc.expectedDeleted, deleted); d != "" {
+				t.Errorf("Wrong PipelineRuns were deleted. Diff %s", diff.PrintWantGot(d))
+			}
+
+			var iterations []string
+			for _, pr := range getCreatedPipelineRuns(clients) {
+				iterations = append(iterations, pr.Labels["custom.tekton.dev/pipelineLoopIteration"])
+			}
+			if d := cmp.Diff(tc.expectedIterations, iterations); d != "" {
+				t.Errorf("PipelineRuns were created for the wrong iterations. Diff %s", diff.PrintWantGot(d))
+			}
+
+			status := &pipelineloopv1alpha1.PipelineLoopRunStatus{}
+			if err := reconciledRun.Status.DecodeExtraFields(status); err != nil {
+				t.Fatalf("DecodeExtraFields error: %v", err.Error())
+			}
+			for name, expected := range tc.expectedRunStatus {
+				if actual, ok := status.PipelineRuns[name]; ok && actual.Pruned != expected.Pruned {
+					t.Errorf("Run status for PipelineRun %s has pruned %t instead of %t", name, actual.Pruned, expected.Pruned)
+				}
+			}
+			checkRunStatus(t, reconciledRun, tc.expectedRunStatus)
+
+			if d := cmp.Diff(tc.expectedResults, reconciledRun.Status.Results); d != "" {
+				t.Errorf("Run results are wrong. Diff %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
@@ -47,7 +47,10 @@ func getPipelineSpec(ctx context.Context, tv1beta1 tektonv1beta1.TektonV1beta1In
 
 func getTaskSpecs(ctx context.Context, tv1beta1 tektonv1beta1.TektonV1beta1Interface, pSpec *v1beta1.PipelineSpec, namespace string) (map[string]*v1beta1.TaskSpec, error) {
 	taskSpecs := map[string]*v1beta1.TaskSpec{}
-	for _, ptask := range pSpec.Tasks {
+	var ptasks []v1beta1.PipelineTask
+	ptasks = append(ptasks, pSpec.Tasks...)
+	ptasks = append(ptasks, pSpec.Finally...)
+	for _, ptask := range ptasks {
 		var taskSpec *v1beta1.TaskSpec
 		if ptask.TaskRef == nil {
 			taskSpec = &ptask.TaskSpec.TaskSpec
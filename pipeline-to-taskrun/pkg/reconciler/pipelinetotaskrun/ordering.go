@@ -18,57 +18,78 @@ package pipelinetotaskrun
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 )
 
-type linkedTask struct {
-	task *v1beta1.PipelineTask
-	next *linkedTask
-}
-
+// putTasksInOrder linearizes the graph formed by the tasks and their dependencies, from runAfter and from result
+// references, into a sequence in which each task comes after all the tasks it depends on. Tasks that don't depend on
+// each other keep the order in which they are declared.
 func putTasksInOrder(tasks []v1beta1.PipelineTask) ([]v1beta1.PipelineTask, error) {
-	seen := map[string]*linkedTask{}
-	var root *linkedTask
-
-	for i := 0; i < len(tasks); i++ {
-		seen[tasks[i].Name] = &linkedTask{task: &tasks[i]}
+	indexes := make(map[string]int, len(tasks))
+	for i, task := range tasks {
+		indexes[task.Name] = i
 	}
 
-	for _, task := range tasks {
-		if len(task.RunAfter) > 1 {
-			return nil, fmt.Errorf("fan in not yet supported but %s has more than one runAfter", task.Name)
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make([]int, len(tasks))
+	// path holds the tasks being visited, so that a cycle can be reported with all the tasks that form it
+	var path []string
+	ordered := make([]v1beta1.PipelineTask, 0, len(tasks))
+
+	var visit func(i int) error
+	visit = func(i int) error {
+		task := tasks[i]
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("tasks can't be put in order because they form a cycle: %s", strings.Join(getCycle(path, task.Name), " -> "))
 		}
-		l, _ := seen[task.Name]
-		if len(task.RunAfter) == 0 {
-			if root != nil {
-				return nil, fmt.Errorf("parallel tasks not yet supported by %s and %s are trying to run in parallel", task.Name, root.task.Name)
-			} else {
-				root = l
-			}
-		} else {
-			before, ok := seen[task.RunAfter[0]]
+		state[i] = visiting
+		path = append(path, task.Name)
+
+		var deps []int
+		for _, dep := range task.Deps() {
+			before, ok := indexes[dep]
 			if !ok {
-				return nil, fmt.Errorf("task %s trying to run after task %s which is not present", task.Name, task.RunAfter[0])
+				return fmt.Errorf("task %s trying to run after task %s which is not present", task.Name, dep)
 			}
-			before.next = l
+			deps = append(deps, before)
 		}
-	}
-	if root == nil {
-		return nil, fmt.Errorf("invalid sequence, there was no starting task (probably a loop?)")
-	}
-
-	ordered := []v1beta1.PipelineTask{*root.task}
-	curr := root.next
-	for {
-		if curr == nil {
-			if len(ordered) < len(tasks) {
-				return nil, fmt.Errorf("sequence was not completely connected, gap after %s", ordered[len(ordered)-1].Name)
+		sort.Ints(deps)
+		for _, before := range deps {
+			if err := visit(before); err != nil {
+				return err
 			}
-			break
 		}
-		ordered = append(ordered, *curr.task)
-		curr = curr.next
+
+		path = path[:len(path)-1]
+		state[i] = visited
+		ordered = append(ordered, task)
+		return nil
 	}
 
+	for i := range tasks {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
 	return ordered, nil
 }
+
+// getCycle returns the tasks of path from the first visit of name, closed by name
+func getCycle(path []string, name string) []string {
+	for i := range path {
+		if path[i] == name {
+			cycle := append([]string{}, path[i:]...)
+			return append(cycle, name)
+		}
+	}
+	return []string{name}
+}
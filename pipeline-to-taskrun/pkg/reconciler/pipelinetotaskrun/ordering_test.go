@@ -5,52 +5,61 @@ import (
 	"testing"
 )
 
-func TestPutTasksInOrderUnsupported(t *testing.T) {
+func TestPutTasksInOrderErrors(t *testing.T) {
 	for _, tc := range []struct {
-		name  string
-		tasks []v1beta1.PipelineTask
+		name          string
+		tasks         []v1beta1.PipelineTask
+		expectedError string
 	}{{
-		name: "parallel",
+		name: "cycle",
 		tasks: []v1beta1.PipelineTask{{
-			Name: "starts",
+			Name:     "first",
+			RunAfter: []string{"second"},
 		}, {
-			Name: "alsostarts",
+			Name:     "second",
+			RunAfter: []string{"first"},
 		}},
+		expectedError: "tasks can't be put in order because they form a cycle: first -> second -> first",
 	}, {
-		name: "fan in",
+		name: "cycle after a task",
 		tasks: []v1beta1.PipelineTask{{
-			Name: "out1",
+			Name: "first",
 		}, {
-			Name: "out2",
+			Name:     "second",
+			RunAfter: []string{"first", "fourth"},
 		}, {
-			Name:     "in",
-			RunAfter: []string{"out1", "out2"},
+			Name:     "third",
+			RunAfter: []string{"second"},
+		}, {
+			Name: "fourth",
+			Params: []v1beta1.Param{{
+				Name:  "p",
+				Value: *v1beta1.NewArrayOrString("$(tasks.third.results.r)"),
+			}},
 		}},
+		expectedError: "tasks can't be put in order because they form a cycle: second -> fourth -> third -> second",
 	}, {
-		name: "fan out",
+		name: "self",
 		tasks: []v1beta1.PipelineTask{{
-			Name: "first",
-		}, {
-			Name:     "out1",
-			RunAfter: []string{"first"},
-		}, {
-			Name:     "out2",
+			Name:     "first",
 			RunAfter: []string{"first"},
 		}},
+		expectedError: "tasks can't be put in order because they form a cycle: first -> first",
 	}, {
-		name: "cycle",
+		name: "missing",
 		tasks: []v1beta1.PipelineTask{{
 			Name:     "first",
-			RunAfter: []string{"second"},
-		}, {
-			Name:     "second",
-			RunAfter: []string{"first"},
+			RunAfter: []string{"zeroth"},
 		}},
+		expectedError: "task first trying to run after task zeroth which is not present",
 	}} {
 		t.Run(tc.name, func(t *testing.T) {
 			_, err := putTasksInOrder(tc.tasks)
 			if err == nil {
-				t.Fatalf("expected error for unsupported tasks but got none")
+				t.Fatalf("expected error for tasks that can't be put in order but got none")
+			}
+			if err.Error() != tc.expectedError {
+				t.Errorf("expected error %q but got %q", tc.expectedError, err.Error())
 			}
 		})
 	}
@@ -133,6 +142,64 @@ func TestPutTasksInOrder(t *testing.T) {
 			Name: "first",
 		}},
 		expectedOrder: []string{"first", "second", "third"},
+	}, {
+		name: "parallel",
+		tasks: []v1beta1.PipelineTask{{
+			Name: "starts",
+		}, {
+			Name: "alsostarts",
+		}},
+		expectedOrder: []string{"starts", "alsostarts"},
+	}, {
+		name: "fan in",
+		tasks: []v1beta1.PipelineTask{{
+			Name:     "in",
+			RunAfter: []string{"out2", "out1"},
+		}, {
+			Name: "out1",
+		}, {
+			Name: "out2",
+		}},
+		expectedOrder: []string{"out1", "out2", "in"},
+	}, {
+		name: "fan out",
+		tasks: []v1beta1.PipelineTask{{
+			Name: "first",
+		}, {
+			Name:     "out1",
+			RunAfter: []string{"first"},
+		}, {
+			Name:     "out2",
+			RunAfter: []string{"first"},
+		}},
+		expectedOrder: []string{"first", "out1", "out2"},
+	}, {
+		name: "diamond",
+		tasks: []v1beta1.PipelineTask{{
+			Name:     "last",
+			RunAfter: []string{"left", "right"},
+		}, {
+			Name:     "right",
+			RunAfter: []string{"first"},
+		}, {
+			Name:     "left",
+			RunAfter: []string{"first"},
+		}, {
+			Name: "first",
+		}},
+		expectedOrder: []string{"first", "right", "left", "last"},
+	}, {
+		name: "resultReference",
+		tasks: []v1beta1.PipelineTask{{
+			Name: "consumer",
+			Params: []v1beta1.Param{{
+				Name:  "digest",
+				Value: *v1beta1.NewArrayOrString("$(tasks.producer.results.digest)"),
+			}},
+		}, {
+			Name: "producer",
+		}},
+		expectedOrder: []string{"producer", "consumer"},
 	}} {
 		t.Run(tc.name, func(t *testing.T) {
 			sequence, err := putTasksInOrder(tc.tasks)
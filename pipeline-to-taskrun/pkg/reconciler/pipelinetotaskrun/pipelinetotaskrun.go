@@ -27,11 +27,13 @@ import (
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	runv1alpha1 "github.com/tektoncd/pipeline/pkg/apis/run/v1alpha1"
 	clientset "github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
 	"github.com/tektoncd/pipeline/pkg/client/injection/reconciler/pipeline/v1alpha1/run"
 	listersalpha "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1alpha1"
 	listers "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1beta1"
 	"github.com/tektoncd/pipeline/pkg/reconciler/events"
+	"github.com/tektoncd/pipeline/pkg/substitution"
 	"go.uber.org/zap"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -132,6 +134,22 @@ func (r *Reconciler) reconcile(ctx context.Context, run *v1alpha1.Run) error {
 	}
 	if tr != nil {
 		logger.Infof("Found a TaskRun object %s", tr.Name)
+		if tr.IsDone() {
+			// the results and the skippable tasks of the pipeline were recorded on the TaskRun when it was created,
+			// so the pipeline isn't needed anymore and may have changed since
+			record, err := getPipelineRecord(tr)
+			if err != nil {
+				logger.Errorf("Run %s/%s couldn't read the pipeline recorded in its TaskRun: %v", run.Namespace, run.Name, err)
+				record = &pipelineRecord{}
+			}
+			if tr.IsSuccessful() {
+				run.Status.Results = getRunResults(record.Results, tr.Status.TaskRunResults)
+			}
+			if err := updateSkippedTasks(run, record.SkippableTasks, tr); err != nil {
+				logger.Errorf("Run %s/%s couldn't record its skipped tasks: %v", run.Namespace, run.Name, err)
+				return fmt.Errorf("couldn't record skipped tasks: %v", err)
+			}
+		}
 		return updateRunStatus(ctx, run, tr)
 	}
 
@@ -208,6 +226,47 @@ func updateRunStatus(ctx context.Context, run *v1alpha1.Run, taskRun *v1beta1.Ta
 	return nil
 }
 
+// getRunResults returns the values of the pipeline results, replacing the references to the results of pipeline tasks
+// with the values of the namespaced results of the TaskRun. Pipeline results that use a result the TaskRun doesn't
+// have are omitted.
+func getRunResults(pipelineResults []v1beta1.PipelineResult, taskRunResults []v1beta1.TaskRunResult) []runv1alpha1.RunResult {
+	values := map[string]string{}
+	for _, r := range taskRunResults {
+		values[r.Name] = r.Value
+	}
+
+	var runResults []runv1alpha1.RunResult
+	for _, pResult := range pipelineResults {
+		replacements := map[string]string{}
+		found := true
+		expressions, _ := v1beta1.GetVarSubstitutionExpressionsForPipelineResult(pResult)
+		for _, ref := range v1beta1.NewResultRefs(expressions) {
+			value, ok := values[namespaceName(ref.PipelineTask, ref.Result)]
+			if !ok {
+				found = false
+				break
+			}
+			replacements[fmt.Sprintf("tasks.%s.results.%s", ref.PipelineTask, ref.Result)] = value
+		}
+		if found {
+			runResults = append(runResults, runv1alpha1.RunResult{
+				Name:  pResult.Name,
+				Value: substitution.ApplyReplacements(pResult.Value, replacements),
+			})
+		}
+	}
+	return runResults
+}
+
+// updateSkippedTasks records the tasks that the TaskRun skipped in the ExtraFields of the Run
+func updateSkippedTasks(run *v1alpha1.Run, skippableTasks []v1beta1.SkippedTask, taskRun *v1beta1.TaskRun) error {
+	skippedTasks := getSkippedTasks(skippableTasks, taskRun.Status.TaskRunResults)
+	if len(skippedTasks) == 0 {
+		return nil
+	}
+	return run.Status.EncodeExtraFields(&ExtraFields{SkippedTasks: skippedTasks})
+}
+
 func getObjectMeta(run *v1alpha1.Run) metav1.ObjectMeta {
 	return metav1.ObjectMeta{
 		Name:            run.Name,
@@ -26,6 +26,7 @@ import (
 	"github.com/tektoncd/experimental/pipeline-to-taskrun/test"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	runv1alpha1 "github.com/tektoncd/pipeline/pkg/apis/run/v1alpha1"
 	ttesting "github.com/tektoncd/pipeline/pkg/reconciler/testing"
 	"github.com/tektoncd/pipeline/test/diff"
 	"github.com/tektoncd/pipeline/test/names"
@@ -151,6 +152,25 @@ func getCreatedTaskRun(clients test.Clients) *v1beta1.TaskRun {
 	return nil
 }
 
+// createTaskRun reconciles a new run that references pipeline and returns the TaskRun created for it
+func createTaskRun(t *testing.T, run *v1alpha1.Run, pipeline *v1beta1.Pipeline) *v1beta1.TaskRun {
+	t.Helper()
+	d := test.Data{
+		Runs:      []*v1alpha1.Run{run},
+		Pipelines: []*v1beta1.Pipeline{pipeline},
+	}
+	testAssets, _ := getController(t, d)
+
+	if err := testAssets.Controller.Reconciler.Reconcile(context.Background(), getRunName(run)); err != nil {
+		t.Fatalf("couldn't reconcile run %v", err)
+	}
+	createdTaskRun := getCreatedTaskRun(testAssets.Clients)
+	if createdTaskRun == nil {
+		t.Fatalf("A TaskRun should have been created but was not")
+	}
+	return createdTaskRun
+}
+
 func running(tr *v1beta1.TaskRun) *v1beta1.TaskRun {
 	trWithStatus := tr.DeepCopy()
 	trWithStatus.Status.SetCondition(&apis.Condition{
@@ -462,6 +482,278 @@ func TestReconcileComplexPipeline(t *testing.T) {
 	}
 }
 
+var pipelineWithResults = `
+metadata:
+  name: pipeline
+  namespace: foo
+spec:
+  tasks:
+  - name: make-result
+    taskSpec:
+      results:
+      - name: amazing
+      steps:
+      - name: make
+        image: ubuntu
+        script: |
+          echo -n "amazing" > $(results.amazing.path)
+  - name: use-result
+    params:
+    - name: foo
+      value: $(tasks.make-result.results.amazing)
+    taskSpec:
+      params:
+      - name: foo
+      steps:
+      - name: use
+        image: ubuntu
+        script: |
+          echo "$(params.foo)"
+  results:
+  - name: amazing-result
+    value: $(tasks.make-result.results.amazing)
+  - name: combined-result
+    value: $(tasks.make-result.results.amazing) and $(tasks.make-result.results.amazing)
+`
+
+func TestReconcileResultsBetweenTasks(t *testing.T) {
+	ctx := context.Background()
+	names.TestingSeed()
+
+	run := test.MustParseRun(t, `
+metadata:
+  name: run-with-pipeline
+  namespace: foo
+spec:
+  ref:
+    apiVersion: tekton.dev/v1alpha1
+    kind: PipelineToTaskRun
+    name: pipeline
+`)
+	d := test.Data{
+		Runs:      []*v1alpha1.Run{run},
+		Pipelines: []*v1beta1.Pipeline{test.MustParsePipeline(t, pipelineWithResults)},
+	}
+	testAssets, _ := getController(t, d)
+
+	if err := testAssets.Controller.Reconciler.Reconcile(ctx, getRunName(run)); err != nil {
+		t.Fatalf("couldn't reconcile run %v", err)
+	}
+
+	expectedTaskRun := test.MustParseTaskRun(t, `
+spec:
+  taskSpec:
+    results:
+    - name: make-result-amazing
+    steps:
+    - name: make-result-make
+      image: ubuntu
+      script: |
+        echo -n "amazing" > $(results.make-result-amazing.path)
+    - name: use-result-use
+      image: ubuntu
+      script: |
+        echo "$(cat /tekton/results/make-result-amazing)"
+`)
+	createdTaskRun := getCreatedTaskRun(testAssets.Clients)
+	if createdTaskRun == nil {
+		t.Fatalf("A TaskRun should have been created but was not")
+	}
+	if d := cmp.Diff(expectedTaskRun.Spec.TaskSpec, createdTaskRun.Spec.TaskSpec, cmpopts.EquateEmpty()); d != "" {
+		t.Errorf("TaskRun spec was different from expected: %s", diff.PrintWantGot(d))
+	}
+	if len(createdTaskRun.Spec.Params) != 0 {
+		t.Errorf("expected the param using a result not to be passed to the TaskRun but got %v", createdTaskRun.Spec.Params)
+	}
+}
+
+func TestReconcileRunResults(t *testing.T) {
+	ctx := context.Background()
+	names.TestingSeed()
+
+	taskRun := successful(createTaskRun(t, runWithPipeline, test.MustParsePipeline(t, pipelineWithResults)))
+	taskRun.Status.TaskRunResults = []v1beta1.TaskRunResult{{
+		Name:  "make-result-amazing",
+		Value: "amazing",
+	}}
+	// the results of the pipeline were recorded when the TaskRun was created, so it doesn't matter that the pipeline
+	// has been deleted since
+	d := test.Data{
+		Runs:     []*v1alpha1.Run{runWithPipeline},
+		TaskRuns: []*v1beta1.TaskRun{taskRun},
+	}
+	testAssets, _ := getController(t, d)
+
+	if err := testAssets.Controller.Reconciler.Reconcile(ctx, getRunName(runWithPipeline)); err != nil {
+		t.Fatalf("couldn't reconcile run %v", err)
+	}
+
+	run, err := testAssets.Clients.Pipeline.TektonV1alpha1().Runs(runWithPipeline.Namespace).Get(ctx, runWithPipeline.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Error getting reconciled run from fake client: %s", err)
+	}
+	if err := checkRunCondition(t, run, corev1.ConditionTrue, v1beta1.TaskRunReasonSuccessful.String(), ""); err != nil {
+		t.Fatalf("run is invalid")
+	}
+	expectedResults := []runv1alpha1.RunResult{{
+		Name:  "amazing-result",
+		Value: "amazing",
+	}, {
+		Name:  "combined-result",
+		Value: "amazing and amazing",
+	}}
+	if d := cmp.Diff(expectedResults, run.Status.Results); d != "" {
+		t.Errorf("Run results were different from expected: %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestReconcileFinally(t *testing.T) {
+	ctx := context.Background()
+	names.TestingSeed()
+
+	d := test.Data{
+		Runs: []*v1alpha1.Run{runWithPipeline},
+		Pipelines: []*v1beta1.Pipeline{test.MustParsePipeline(t, `
+metadata:
+  name: pipeline
+  namespace: foo
+spec:
+  tasks:
+  - name: second
+    runAfter: [first]
+    taskSpec:
+      steps:
+      - name: test
+        image: ubuntu
+        script: echo second
+  - name: first
+    taskSpec:
+      steps:
+      - name: clone
+        image: ubuntu
+        script: echo first
+  finally:
+  - name: cleanup
+    taskSpec:
+      steps:
+      - name: clean
+        image: ubuntu
+        script: echo cleanup
+  - name: notify
+    taskSpec:
+      steps:
+      - name: send
+        image: ubuntu
+        command: ["echo"]
+        args: ["notify"]
+`)},
+	}
+	testAssets, _ := getController(t, d)
+
+	if err := testAssets.Controller.Reconciler.Reconcile(ctx, getRunName(runWithPipeline)); err != nil {
+		t.Fatalf("couldn't reconcile run %v", err)
+	}
+
+	createdTaskRun := getCreatedTaskRun(testAssets.Clients)
+	if createdTaskRun == nil {
+		t.Fatalf("A TaskRun should have been created but was not")
+	}
+	var stepNames []string
+	for _, step := range createdTaskRun.Spec.TaskSpec.Steps {
+		stepNames = append(stepNames, step.Name)
+	}
+	expectedStepNames := []string{"first-clone", "second-test", "cleanup-clean", "notify-send"}
+	if d := cmp.Diff(expectedStepNames, stepNames); d != "" {
+		t.Errorf("steps were not in the expected order: %s", diff.PrintWantGot(d))
+	}
+	steps := createdTaskRun.Spec.TaskSpec.Steps
+	if !strings.Contains(steps[1].Script, "because an earlier step failed") {
+		t.Errorf("expected the steps of regular tasks to be skipped after a failure but script was %s", steps[1].Script)
+	}
+	if strings.Contains(steps[2].Script, "because an earlier step failed") {
+		t.Errorf("expected the steps of finally tasks to always run but script was %s", steps[2].Script)
+	}
+	if !strings.Contains(steps[3].Script, "exit 1") {
+		t.Errorf("expected the last step to fail if any step failed but script was %s", steps[3].Script)
+	}
+}
+
+func TestReconcileSkippedTasks(t *testing.T) {
+	ctx := context.Background()
+	names.TestingSeed()
+
+	pipeline := test.MustParsePipeline(t, `
+metadata:
+  name: pipeline
+  namespace: foo
+spec:
+  params:
+  - name: branch
+  tasks:
+  - name: test
+    taskSpec:
+      steps:
+      - name: test
+        image: ubuntu
+        script: echo test
+  - name: release
+    runAfter: [test]
+    when:
+    - input: $(params.branch)
+      operator: in
+      values: ["main"]
+    taskSpec:
+      steps:
+      - name: release
+        image: ubuntu
+        script: echo release
+`)
+	run := runWithPipeline.DeepCopy()
+	run.Spec.Params = []v1beta1.Param{{Name: "branch", Value: *v1beta1.NewArrayOrString("feature")}}
+	taskRun := successful(createTaskRun(t, run, pipeline))
+	taskRun.Status.TaskRunResults = []v1beta1.TaskRunResult{{
+		Name:  "pipeline-to-taskrun-skipped-tasks",
+		Value: "release\n",
+	}}
+	// the skipped tasks are reported with the when expressions the TaskRun was created with, not with those of the
+	// pipeline after it was edited
+	editedPipeline := pipeline.DeepCopy()
+	editedPipeline.Spec.Tasks[1].WhenExpressions[0].Values = []string{"main", "feature"}
+	d := test.Data{
+		Runs:      []*v1alpha1.Run{run},
+		Pipelines: []*v1beta1.Pipeline{editedPipeline},
+		TaskRuns:  []*v1beta1.TaskRun{taskRun},
+	}
+	testAssets, _ := getController(t, d)
+
+	if err := testAssets.Controller.Reconciler.Reconcile(ctx, getRunName(run)); err != nil {
+		t.Fatalf("couldn't reconcile run %v", err)
+	}
+
+	reconciledRun, err := testAssets.Clients.Pipeline.TektonV1alpha1().Runs(run.Namespace).Get(ctx, run.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Error getting reconciled run from fake client: %s", err)
+	}
+	if err := checkRunCondition(t, reconciledRun, corev1.ConditionTrue, v1beta1.TaskRunReasonSuccessful.String(), ""); err != nil {
+		t.Fatalf("run is invalid")
+	}
+	extraFields := &ExtraFields{}
+	if err := reconciledRun.Status.DecodeExtraFields(extraFields); err != nil {
+		t.Fatalf("couldn't decode extra fields of run: %v", err)
+	}
+	expectedSkippedTasks := []v1beta1.SkippedTask{{
+		Name: "release",
+		WhenExpressions: v1beta1.WhenExpressions{{
+			Input:    "feature",
+			Operator: "in",
+			Values:   []string{"main"},
+		}},
+	}}
+	if d := cmp.Diff(expectedSkippedTasks, extraFields.SkippedTasks); d != "" {
+		t.Errorf("Run skipped tasks were different from expected: %s", diff.PrintWantGot(d))
+	}
+}
+
 func TestReconcileUnsupported(t *testing.T) {
 	run := `
 metadata:
@@ -479,26 +771,6 @@ spec:
 		pipeline        *v1beta1.Pipeline
 		run             *v1alpha1.Run
 	}{{
-		name:            "pipeline results - TODO (community#447)",
-		expectedErrText: []string{"results"},
-		pipeline: test.MustParsePipeline(t, `
-metadata:
-  name: pipeline
-  namespace: foo
-spec:
-  tasks:
-  - name: make-result
-    taskSpec:
-      steps:
-      - image: ubuntu
-      results:
-      - name: amazing
-  results:
-  - name: amazing-result
-    value: $(tasks.make-result.results.amazing)
-`),
-		run: test.MustParseRun(t, run),
-	}, {
 		name:            "array params - TODO (community#447)",
 		expectedErrText: []string{"array"},
 		pipeline: test.MustParsePipeline(t, `
@@ -673,32 +945,6 @@ spec:
           description: this embedded task uses labels and annotations
       steps:
       - image: ubuntu
-`),
-		run: test.MustParseRun(t, run),
-	}, {
-		name:            "results between tasks",
-		expectedErrText: []string{"result"},
-		pipeline: test.MustParsePipeline(t, `
-metadata:
-  name: pipeline
-  namespace: foo
-spec:
-  tasks:
-  - name: make-result
-    taskSpec:
-      steps:
-      - image: ubuntu
-      results:
-      - name: amazing
-  - name: use-result
-    params:
-    - name: foo
-      value: $(tasks.make-result.results.amazing)
-    taskSpec:
-      params:
-      - name: foo
-      steps:
-      - image: ubuntu
 `),
 		run: test.MustParseRun(t, run),
 	}, {
@@ -766,27 +1012,8 @@ spec:
 `),
 		run: test.MustParseRun(t, run),
 	}, {
-		name:            "when expressions - new approach required to support",
-		expectedErrText: []string{"when"},
-		pipeline: test.MustParsePipeline(t, `
-metadata:
-  name: pipeline
-  namespace: foo
-spec:
-  tasks:
-  - name: some-task
-    when:
-      - input: "foo"
-        operator: in
-        values: ["bar"]    
-    taskSpec:
-      steps:
-      - image: ubuntu
-`),
-		run: test.MustParseRun(t, run),
-	}, {
-		name:            "finally tasks - new approach required to support",
-		expectedErrText: []string{"finally"},
+		name:            "finally tasks with steps that only use the entrypoint of the image",
+		expectedErrText: []string{"script or a command"},
 		pipeline: test.MustParsePipeline(t, `
 metadata:
   name: pipeline
@@ -805,27 +1032,8 @@ spec:
 `),
 		run: test.MustParseRun(t, run),
 	}, {
-		name:            "parallel tasks (two, starting immediately) - new approach required to support",
-		expectedErrText: []string{"parallel"},
-		pipeline: test.MustParsePipeline(t, `
-metadata:
-  name: pipeline
-  namespace: foo
-spec:
-  tasks:
-  - name: a-parallel-task
-    taskSpec:
-      steps:
-      - image: ubuntu
-  - name: another-parallel-task
-    taskSpec:
-      steps:
-      - image: ubuntu
-`),
-		run: test.MustParseRun(t, run),
-	}, {
-		name:            "parallel tasks (branch after first task) - new approach required to support",
-		expectedErrText: []string{"parallel"},
+		name:            "tasks in a cycle",
+		expectedErrText: []string{"cycle"},
 		pipeline: test.MustParsePipeline(t, `
 metadata:
   name: pipeline
@@ -833,15 +1041,11 @@ metadata:
 spec:
   tasks:
   - name: first-task
+    runAfter: [second-task]
     taskSpec:
       steps:
       - image: ubuntu
-  - name: a-parallel-task
-    runAfter: [first-task]
-    taskSpec:
-      steps:
-      - image: ubuntu
-  - name: another-parallel-task
+  - name: second-task
     runAfter: [first-task]
     taskSpec:
       steps:
@@ -18,13 +18,23 @@ package pipelinetotaskrun
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
+
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"github.com/tektoncd/pipeline/pkg/names"
 	"github.com/tektoncd/pipeline/pkg/reconciler/pipelinerun/resources"
 	resources2 "github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources"
 	"github.com/tektoncd/pipeline/pkg/substitution"
+	corev1 "k8s.io/api/core/v1"
 )
 
+// resultsDir is the directory in which the steps of a TaskRun write their results
+const resultsDir = "/tekton/results"
+
+// resultReadRegex matches the reads of results returned by getResultRead
+var resultReadRegex = regexp.MustCompile(`\$\(cat ` + regexp.QuoteMeta(resultsDir) + `/[^)]+\)`)
+
 // PipelineTaskInfo holds all of the info needed to run a pipeline task
 type PipelineTaskInfo struct {
 	// Name is the name of the pipeline Task
@@ -204,3 +214,204 @@ func (pti PipelineTaskInfo) RenameWorkspaces(newMapping map[string]string) Pipel
 
 	return updatedPti
 }
+
+// NamespaceResults will return a new PipelineTaskInfo in which the names of all the declared results are updated such
+// that the result name is prefaced by the name of the pipeline task. All uses of the results' paths will be updated in
+// the steps as well.
+func (pti PipelineTaskInfo) NamespaceResults() PipelineTaskInfo {
+	updatedPti := PipelineTaskInfo{
+		Name:                pti.Name,
+		TaskDeclaredParams:  pti.TaskDeclaredParams,
+		ProvidedParamValues: pti.ProvidedParamValues,
+	}
+
+	replacements := map[string]string{}
+	for _, r := range pti.Results {
+		rName := namespaceName(pti.Name, r.Name)
+		updatedPti.Results = append(updatedPti.Results, v1beta1.TaskResult{
+			Name:        rName,
+			Description: r.Description,
+		})
+		// this is the format that ApplyReplacements expects the replacements to arrive in; it infers the surrounding
+		// dollar sign and brackets
+		existing := fmt.Sprintf("results.%s.path", r.Name)
+		renamed := fmt.Sprintf("$(results.%s.path)", rName)
+		replacements[existing] = renamed
+	}
+
+	updatedTaskSpec := resources2.ApplyReplacements(&v1beta1.TaskSpec{Steps: pti.Steps}, replacements, nil)
+	updatedPti.Steps = updatedTaskSpec.Steps
+	return updatedPti
+}
+
+// ApplyResultReferences will return a new PipelineTaskInfo in which the provided param values that reference the
+// results of other pipeline tasks are no longer passed as params. Instead, all uses of these params in the steps are
+// replaced with a read of the file that the earlier step wrote the result to, which has been written by the time the
+// step runs since all pipeline tasks run as steps of the same TaskRun. The read is a shell command substitution, so it
+// is evaluated in scripts and in args passed to a shell; steps that run a command are changed to run it from a shell
+// script and env vars that use it are exported by the script of the step. Expects the results to have been namespaced.
+func (pti PipelineTaskInfo) ApplyResultReferences() (PipelineTaskInfo, error) {
+	updatedPti := PipelineTaskInfo{
+		Name:    pti.Name,
+		Results: pti.Results,
+	}
+
+	// create a mapping of the params that use results to the values that read them
+	replacements := map[string]string{}
+	for _, p := range pti.ProvidedParamValues {
+		expressions, ok := v1beta1.GetVarSubstitutionExpressionsForParam(p)
+		refs := v1beta1.NewResultRefs(expressions)
+		if !ok || len(refs) == 0 {
+			updatedPti.ProvidedParamValues = append(updatedPti.ProvidedParamValues, p)
+			continue
+		}
+		reads := map[string]string{}
+		for _, ref := range refs {
+			reads[fmt.Sprintf("tasks.%s.results.%s", ref.PipelineTask, ref.Result)] = getResultRead(ref.PipelineTask, ref.Result)
+		}
+		replacements[fmt.Sprintf("params.%s", p.Name)] = substitution.ApplyReplacements(p.Value.StringVal, reads)
+	}
+	for _, p := range pti.TaskDeclaredParams {
+		if _, ok := replacements[fmt.Sprintf("params.%s", p.Name)]; !ok {
+			updatedPti.TaskDeclaredParams = append(updatedPti.TaskDeclaredParams, p)
+		}
+	}
+
+	updatedTaskSpec := resources2.ApplyReplacements(&v1beta1.TaskSpec{Steps: pti.Steps}, replacements, nil)
+	for _, step := range updatedTaskSpec.Steps {
+		updatedStep, err := execResultReads(step)
+		if err != nil {
+			return PipelineTaskInfo{}, err
+		}
+		updatedStep, err = exportResultReads(updatedStep)
+		if err != nil {
+			return PipelineTaskInfo{}, err
+		}
+		updatedPti.Steps = append(updatedPti.Steps, updatedStep)
+	}
+	return updatedPti, nil
+}
+
+// getResultRead returns the shell command substitution that reads the result of a pipeline task once its results
+// have been namespaced.
+func getResultRead(ptaskName, resultName string) string {
+	return fmt.Sprintf("$(cat %s/%s)", resultsDir, namespaceName(ptaskName, resultName))
+}
+
+// execResultReads will change a step whose command or args read results into a step that runs its command from a
+// shell script, since the command and args of a step are not evaluated by a shell. A step that passes its command
+// string to a shell with -c is left as it is if only the command string reads results, since that shell evaluates them.
+func execResultReads(step v1beta1.Step) (v1beta1.Step, error) {
+	words := append(append([]string{}, step.Command...), step.Args...)
+	commandString := getShellCommandString(words)
+	readsResults := false
+	for i, w := range words {
+		if i != commandString && resultReadRegex.MatchString(w) {
+			readsResults = true
+		}
+	}
+	if !readsResults {
+		return step, nil
+	}
+	if step.Script != "" || len(step.Command) == 0 {
+		return step, fmt.Errorf("args of step %s use a result, which is only supported in steps with a command", step.Name)
+	}
+
+	var run []string
+	for _, w := range words {
+		run = append(run, shellQuoteReads(w))
+	}
+	updatedStep := step.DeepCopy()
+	updatedStep.Script = fmt.Sprintf("#!/bin/sh\nexec %s\n", strings.Join(run, " "))
+	updatedStep.Command = nil
+	updatedStep.Args = nil
+	return *updatedStep, nil
+}
+
+// getShellCommandString returns the index of the command string in words if they run a shell with -c, otherwise -1
+func getShellCommandString(words []string) int {
+	if len(words) == 0 || !strings.HasSuffix(words[0], "sh") {
+		return -1
+	}
+	withCommandString := false
+	for i, w := range words[1:] {
+		switch {
+		case w == "--":
+			if withCommandString && i+2 < len(words) {
+				return i + 2
+			}
+			return -1
+		case !strings.HasPrefix(w, "-"):
+			if withCommandString {
+				return i + 1
+			}
+			return -1
+		case !strings.HasPrefix(w, "--") && strings.Contains(w[1:], "c"):
+			withCommandString = true
+		}
+	}
+	return -1
+}
+
+// shellQuoteReads returns s quoted so that a shell uses it as one word in which only the reads of results are
+// evaluated
+func shellQuoteReads(s string) string {
+	var word strings.Builder
+	last := 0
+	for _, match := range resultReadRegex.FindAllStringIndex(s, -1) {
+		if match[0] > last {
+			word.WriteString(shellQuoteAll([]string{s[last:match[0]]})[0])
+		}
+		fmt.Fprintf(&word, `"%s"`, s[match[0]:match[1]])
+		last = match[1]
+	}
+	if last < len(s) || word.Len() == 0 {
+		word.WriteString(shellQuoteAll([]string{s[last:]})[0])
+	}
+	return word.String()
+}
+
+// exportResultReads will move the env vars of the step whose values read results into exports at the start of its
+// script, since the values of env vars are not evaluated by a shell.
+func exportResultReads(step v1beta1.Step) (v1beta1.Step, error) {
+	var env []corev1.EnvVar
+	var exports []string
+	for _, e := range step.Env {
+		if !strings.Contains(e.Value, "$(cat "+resultsDir+"/") {
+			env = append(env, e)
+			continue
+		}
+		if !isShellScript(step.Script) {
+			return step, fmt.Errorf("env var %s of step %s uses a result, which is only supported in steps with a shell script", e.Name, step.Name)
+		}
+		value := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "`", "\\`").Replace(e.Value)
+		exports = append(exports, fmt.Sprintf("export %s=\"%s\"\n", e.Name, value))
+	}
+	if len(exports) == 0 {
+		return step, nil
+	}
+
+	updatedStep := step.DeepCopy()
+	updatedStep.Env = env
+	// the exports go after the shebang, if there is one
+	shebang, script := "", step.Script
+	if strings.HasPrefix(script, "#!") {
+		if i := strings.Index(script, "\n"); i >= 0 {
+			shebang, script = script[:i+1], script[i+1:]
+		}
+	}
+	updatedStep.Script = shebang + strings.Join(exports, "") + script
+	return *updatedStep, nil
+}
+
+// isShellScript returns true if the script is run by a shell, which is the case for scripts without a shebang
+func isShellScript(script string) bool {
+	if script == "" {
+		return false
+	}
+	if !strings.HasPrefix(script, "#!") {
+		return true
+	}
+	interpreter := strings.Fields(strings.SplitN(script, "\n", 2)[0][2:])
+	return len(interpreter) > 0 && strings.HasSuffix(interpreter[len(interpreter)-1], "sh")
+}
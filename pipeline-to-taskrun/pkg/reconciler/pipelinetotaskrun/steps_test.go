@@ -446,3 +446,211 @@ func TestRenameWorkspaces(t *testing.T) {
 		t.Errorf("didn't get expected updated info. Diff: %s", diff.PrintWantGot(d))
 	}
 }
+
+func TestNamespaceResults(t *testing.T) {
+	pti := parsePipelineTaskInfo(t, "grab-source", `
+  - name: grab-source-url
+    description: "git url to clone"
+`, `
+    - name: grab-source-url
+      value: "https://github.com/tektoncd/chains"
+`, `
+  - name: clone
+    image: some-git-image
+    script: |
+      echo -n "$RESULT_SHA" > $(results.commit.path)
+      echo -n "$(params.grab-source-url)" > $(results.url.path)
+`, `
+  - name: commit
+    description: "The precise commit SHA that was fetched by this Task"
+  - name: url
+    description: "The precise URL that was fetched by this Task"
+`)
+	expected := parsePipelineTaskInfo(t, "grab-source", `
+  - name: grab-source-url
+    description: "git url to clone"
+`, `
+    - name: grab-source-url
+      value: "https://github.com/tektoncd/chains"
+`, `
+  - name: clone
+    image: some-git-image
+    script: |
+      echo -n "$RESULT_SHA" > $(results.grab-source-commit.path)
+      echo -n "$(params.grab-source-url)" > $(results.grab-source-url.path)
+`, `
+  - name: grab-source-commit
+    description: "The precise commit SHA that was fetched by this Task"
+  - name: grab-source-url
+    description: "The precise URL that was fetched by this Task"
+`)
+	updatedPti := pti.NamespaceResults()
+	if d := cmp.Diff(expected, updatedPti); d != "" {
+		t.Errorf("didn't get expected updated info. Diff: %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestApplyResultReferences(t *testing.T) {
+	for _, tc := range []struct {
+		Name             string
+		PipelineTaskInfo PipelineTaskInfo
+		Expected         PipelineTaskInfo
+	}{{
+		Name: "no result references",
+		PipelineTaskInfo: parsePipelineTaskInfo(t, "run-tests", `
+  - name: run-tests-package
+`, `
+    - name: run-tests-package
+      value: github.com/tektoncd/chains
+`, `
+  - name: run-tests-unit-test
+    image: golang
+    script: |
+      go test $(params.run-tests-package)
+`, ``),
+		Expected: parsePipelineTaskInfo(t, "run-tests", `
+  - name: run-tests-package
+`, `
+    - name: run-tests-package
+      value: github.com/tektoncd/chains
+`, `
+  - name: run-tests-unit-test
+    image: golang
+    script: |
+      go test $(params.run-tests-package)
+`, ``),
+	}, {
+		Name: "result references in script, args and env",
+		PipelineTaskInfo: parsePipelineTaskInfo(t, "run-tests", `
+  - name: run-tests-package
+  - name: run-tests-revision
+  - name: run-tests-url
+`, `
+    - name: run-tests-package
+      value: github.com/tektoncd/chains
+    - name: run-tests-revision
+      value: $(tasks.grab-source.results.commit)
+    - name: run-tests-url
+      value: "cloned $(tasks.grab-source.results.url) at $(tasks.grab-source.results.commit)"
+`, `
+  - name: run-tests-unit-test
+    image: golang
+    env:
+    - name: PACKAGE
+      value: $(params.run-tests-package)
+    - name: URL
+      value: $(params.run-tests-url)
+    script: |
+      #!/usr/bin/env sh
+      echo "$URL"
+      git checkout $(params.run-tests-revision)
+      go test $PACKAGE
+  - name: run-tests-report
+    image: ubuntu
+    command: ["sh", "-c"]
+    args: ["echo $(params.run-tests-revision)"]
+`, ``),
+		Expected: parsePipelineTaskInfo(t, "run-tests", `
+  - name: run-tests-package
+`, `
+    - name: run-tests-package
+      value: github.com/tektoncd/chains
+`, `
+  - name: run-tests-unit-test
+    image: golang
+    env:
+    - name: PACKAGE
+      value: $(params.run-tests-package)
+    script: |
+      #!/usr/bin/env sh
+      export URL="cloned $(cat /tekton/results/grab-source-url) at $(cat /tekton/results/grab-source-commit)"
+      echo "$URL"
+      git checkout $(cat /tekton/results/grab-source-commit)
+      go test $PACKAGE
+  - name: run-tests-report
+    image: ubuntu
+    command: ["sh", "-c"]
+    args: ["echo $(cat /tekton/results/grab-source-commit)"]
+`, ``),
+	}, {
+		Name: "result references in command and args",
+		PipelineTaskInfo: parsePipelineTaskInfo(t, "run-tests", `
+  - name: run-tests-revision
+  - name: run-tests-url
+`, `
+    - name: run-tests-revision
+      value: $(tasks.grab-source.results.commit)
+    - name: run-tests-url
+      value: $(tasks.grab-source.results.url)
+`, `
+  - name: run-tests-report
+    image: ubuntu
+    command: ["echo"]
+    args: ["$(params.run-tests-revision)", "from $(params.run-tests-url)/tree"]
+  - name: run-tests-notify
+    image: ubuntu
+    command: ["bash", "-c", "--"]
+    args: ["echo \"$1\"", "notify", "$(params.run-tests-url)"]
+`, ``),
+		Expected: parsePipelineTaskInfo(t, "run-tests", ``, ``, `
+  - name: run-tests-report
+    image: ubuntu
+    script: |
+      #!/bin/sh
+      exec 'echo' "$(cat /tekton/results/grab-source-commit)" 'from '"$(cat /tekton/results/grab-source-url)"'/tree'
+  - name: run-tests-notify
+    image: ubuntu
+    script: |
+      #!/bin/sh
+      exec 'bash' '-c' '--' 'echo "$1"' 'notify' "$(cat /tekton/results/grab-source-url)"
+`, ``),
+	}} {
+		t.Run(tc.Name, func(t *testing.T) {
+			updatedPti, err := tc.PipelineTaskInfo.ApplyResultReferences()
+			if err != nil {
+				t.Fatalf("didn't expect error applying result references but got %v", err)
+			}
+			if d := cmp.Diff(tc.Expected, updatedPti); d != "" {
+				t.Errorf("didn't get expected updated info. Diff: %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestApplyResultReferencesArgsWithoutCommand(t *testing.T) {
+	pti := parsePipelineTaskInfo(t, "run-tests", `
+  - name: run-tests-revision
+`, `
+    - name: run-tests-revision
+      value: $(tasks.grab-source.results.commit)
+`, `
+  - name: run-tests-unit-test
+    image: golang
+    args: ["$(params.run-tests-revision)"]
+`, ``)
+	if _, err := pti.ApplyResultReferences(); err == nil {
+		t.Errorf("expected error using a result in the args of a step without a command but got none")
+	}
+}
+
+func TestApplyResultReferencesEnvWithoutShell(t *testing.T) {
+	pti := parsePipelineTaskInfo(t, "run-tests", `
+  - name: run-tests-revision
+`, `
+    - name: run-tests-revision
+      value: $(tasks.grab-source.results.commit)
+`, `
+  - name: run-tests-unit-test
+    image: python
+    env:
+    - name: REVISION
+      value: $(params.run-tests-revision)
+    script: |
+      #!/usr/bin/env python3
+      import os
+      print(os.environ["REVISION"])
+`, ``)
+	if _, err := pti.ApplyResultReferences(); err == nil {
+		t.Errorf("expected error using a result in an env var of a python script but got none")
+	}
+}
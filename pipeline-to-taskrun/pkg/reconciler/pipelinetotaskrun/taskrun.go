@@ -1,16 +1,51 @@
 package pipelinetotaskrun
 
 import (
+	"encoding/json"
 	"fmt"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 )
 
+// pipelineRecordAnnotation is the annotation of the TaskRun in which the pipelineRecord is stored
+const pipelineRecordAnnotation = pipeline.GroupName + "/pipelineToTaskRun"
+
+// pipelineRecord holds the parts of the pipeline that are still needed once the TaskRun is done. It is recorded on the
+// TaskRun when it is created so that the Run reports what the TaskRun actually ran even if the pipeline is changed or
+// deleted in the meantime.
+type pipelineRecord struct {
+	// Results are the results of the pipeline
+	// +optional
+	Results []v1beta1.PipelineResult `json:"results,omitempty"`
+	// SkippableTasks are the pipeline tasks that can be skipped, with the params of the Run applied to their when
+	// expressions
+	// +optional
+	SkippableTasks []v1beta1.SkippedTask `json:"skippableTasks,omitempty"`
+}
+
+// getPipelineRecord returns the pipelineRecord stored on the TaskRun. A TaskRun without the annotation has nothing
+// recorded.
+func getPipelineRecord(tr *v1beta1.TaskRun) (*pipelineRecord, error) {
+	record := &pipelineRecord{}
+	value, ok := tr.Annotations[pipelineRecordAnnotation]
+	if !ok {
+		return record, nil
+	}
+	if err := json.Unmarshal([]byte(value), record); err != nil {
+		return nil, fmt.Errorf("couldn't decode annotation %s: %v", pipelineRecordAnnotation, err)
+	}
+	return record, nil
+}
+
 func getMergedTaskRun(run *v1alpha1.Run, pSpec *v1beta1.PipelineSpec, taskSpecs map[string]*v1beta1.TaskSpec) (*v1beta1.TaskRun, error) {
 	sequence, err := putTasksInOrder(pSpec.Tasks)
 	if err != nil {
 		return nil, fmt.Errorf("couldn't find valid order for tasks: %v", err)
 	}
+	// finally tasks can't depend on each other so they run after all the other tasks in the order they are declared
+	sequence = append(sequence, pSpec.Finally...)
 
 	// we'll be declaring and mapping one workspace per provided workspace and eliminating the indirection added by the
 	// workspaces declared by the Task. This will make sure that is volume claim templates are used, only one volume
@@ -51,23 +86,66 @@ func getMergedTaskRun(run *v1alpha1.Run, pSpec *v1beta1.PipelineSpec, taskSpecs
 		})
 	}
 
-	for _, pTask := range sequenceWithAppliedParams {
+	// the tasks that can be skipped, either because of their when expressions or because they run after or use the
+	// results of tasks that can be skipped
+	skippable := map[string]bool{}
+	record := pipelineRecord{Results: pSpec.Results}
+	for i, pTask := range sequenceWithAppliedParams {
 		pti, err := NewPipelineTaskInfo(pTask, taskSpecs)
 		if err != nil {
 			return nil, fmt.Errorf("couldn't construct object to hold pipeline task info for %s: %v", pTask.Name, err)
 		}
 
 		pti = pti.NamespaceParams()
+		pti = pti.NamespaceResults()
+		pti, err = pti.ApplyResultReferences()
+		if err != nil {
+			return nil, fmt.Errorf("couldn't use the results of other tasks in pipeline task %s: %v", pTask.Name, err)
+		}
 		pti = pti.NamespaceSteps()
 		pti = pti.RenameWorkspaces(newWorkspaceMapping[pTask.Name])
 
+		wrapping := StepWrapping{
+			SkipCondition: getSkipCondition(pTask.WhenExpressions, pTask.Deps(), skippable),
+		}
+		if wrapping.SkipCondition != "" {
+			skippable[pTask.Name] = true
+			record.SkippableTasks = append(record.SkippableTasks, v1beta1.SkippedTask{
+				Name:            pTask.Name,
+				WhenExpressions: pTask.WhenExpressions,
+			})
+		}
+		// if there are finally tasks, a failed step can't stop the TaskRun because they must still run
+		if len(pSpec.Finally) > 0 {
+			wrapping.RecordFailures = true
+			wrapping.SkipAfterFailure = i < len(pSpec.Tasks)
+			wrapping.ReportFailures = i == len(sequenceWithAppliedParams)-1
+		}
+		pti, err = pti.WrapSteps(wrapping)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't wrap steps of pipeline task %s: %v", pTask.Name, err)
+		}
+
 		tr.Spec.Params = append(tr.Spec.Params, pti.ProvidedParamValues...)
 		tr.Spec.TaskSpec.Params = append(tr.Spec.TaskSpec.Params, pti.TaskDeclaredParams...)
 		tr.Spec.TaskSpec.Steps = append(tr.Spec.TaskSpec.Steps, pti.Steps...)
-		// we don't support mapping results but we need to declare them in order for steps that write
-		// results to be able to write to the dirs they expect
+		// the results are namespaced so that the results of different pipeline tasks can't collide
 		tr.Spec.TaskSpec.Results = append(tr.Spec.TaskSpec.Results, pti.Results...)
 	}
+	if len(skippable) > 0 {
+		tr.Spec.TaskSpec.Results = append(tr.Spec.TaskSpec.Results, v1beta1.TaskResult{
+			Name:        skippedTasksResult,
+			Description: "The pipeline tasks that were skipped",
+		})
+	}
+
+	if len(record.Results) > 0 || len(record.SkippableTasks) > 0 {
+		b, err := json.Marshal(record)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't encode the results and skippable tasks of the pipeline: %v", err)
+		}
+		tr.Annotations[pipelineRecordAnnotation] = string(b)
+	}
 
 	return tr, nil
 }
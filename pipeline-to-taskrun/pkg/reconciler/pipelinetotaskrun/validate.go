@@ -42,9 +42,6 @@ func validatePipelineTask(pTask *v1beta1.PipelineTask) error {
 	if pTask.Retries != 0 {
 		return fmt.Errorf("task level retries are not yet supported; declared a %d retries", pTask.Retries)
 	}
-	if len(pTask.WhenExpressions) > 0 {
-		return fmt.Errorf("when expressions are not supported")
-	}
 	if len(pTask.Conditions) > 0 {
 		return fmt.Errorf("conditions are not supported")
 	}
@@ -109,18 +106,16 @@ func validateTaskSpec(taskSpec *v1beta1.TaskSpec) error {
 }
 
 func validatePipelineSpec(pSpec *v1beta1.PipelineSpec) error {
-	if len(pSpec.Finally) > 0 {
-		return fmt.Errorf("finally tasks are not supported")
-	}
-
-	if len(pSpec.Results) > 0 {
-		return fmt.Errorf("mapping of pipeline level results not supported")
-	}
 	for _, pTask := range pSpec.Tasks {
 		if err := validatePipelineTask(&pTask); err != nil {
 			return fmt.Errorf("pipeline task %s is invalid: %v", pTask.Name, err)
 		}
 	}
+	for _, pTask := range pSpec.Finally {
+		if err := validatePipelineTask(&pTask); err != nil {
+			return fmt.Errorf("finally task %s is invalid: %v", pTask.Name, err)
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,123 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinetotaskrun
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// skippedTasksResult is the result of the TaskRun in which the steps of skipped pipeline tasks record the names of
+// the tasks, one per line
+const skippedTasksResult = "pipeline-to-taskrun-skipped-tasks"
+
+// resultRefRegex matches references to the results of pipeline tasks
+var resultRefRegex = regexp.MustCompile(`\$\(tasks\.([^.)]+)\.results\.([^.)]+)\)`)
+
+// ExtraFields are the fields recorded in the ExtraFields of the status of a Run. They are named like the equivalent
+// fields of the status of a PipelineRun.
+type ExtraFields struct {
+	// SkippedTasks are the pipeline tasks that were skipped because of their when expressions
+	// +optional
+	SkippedTasks []v1beta1.SkippedTask `json:"skippedTasks,omitempty"`
+}
+
+// getSkipCondition returns a shell condition which is true if a pipeline task must be skipped, either because one of
+// its parents, the tasks it runs after or whose results it uses, is a task in skippable which was skipped or because
+// one of its when expressions is false. If the task can never be skipped, an empty string is returned.
+func getSkipCondition(whenExpressions v1beta1.WhenExpressions, parents []string, skippable map[string]bool) string {
+	var conditions []string
+	for _, parent := range parents {
+		if skippable[parent] {
+			conditions = append(conditions, fmt.Sprintf("grep -qxF %s %s/%s 2>/dev/null", parent, resultsDir, skippedTasksResult))
+		}
+	}
+	for _, we := range whenExpressions {
+		var matches []string
+		for _, v := range we.Values {
+			matches = append(matches, fmt.Sprintf("[ %s = %s ]", getShellWord(we.Input), getShellWord(v)))
+		}
+		matchesAny := fmt.Sprintf("{ %s; }", strings.Join(matches, " || "))
+		if we.Operator == selection.NotIn {
+			conditions = append(conditions, matchesAny)
+		} else {
+			conditions = append(conditions, "! "+matchesAny)
+		}
+	}
+	return strings.Join(conditions, " || ")
+}
+
+// getShellWord returns s quoted so that a shell uses it as one word, with the references to results replaced with
+// reads of the results
+func getShellWord(s string) string {
+	var word strings.Builder
+	quoteLiteral := func(literal string) {
+		if literal != "" {
+			word.WriteString(shellQuoteAll([]string{literal})[0])
+		}
+	}
+
+	last := 0
+	for _, match := range resultRefRegex.FindAllStringSubmatchIndex(s, -1) {
+		quoteLiteral(s[last:match[0]])
+		fmt.Fprintf(&word, `"%s"`, getResultRead(s[match[2]:match[3]], s[match[4]:match[5]]))
+		last = match[1]
+	}
+	quoteLiteral(s[last:])
+
+	if word.Len() == 0 {
+		return "''"
+	}
+	return word.String()
+}
+
+// getSkippedTasks returns the skippable tasks recorded as skipped in the results of the TaskRun, with the results
+// they use in their when expressions replaced by the values of the results of the TaskRun
+func getSkippedTasks(skippableTasks []v1beta1.SkippedTask, taskRunResults []v1beta1.TaskRunResult) []v1beta1.SkippedTask {
+	skipped := map[string]bool{}
+	values := map[string]string{}
+	for _, r := range taskRunResults {
+		values[r.Name] = r.Value
+	}
+	for _, name := range strings.Fields(values[skippedTasksResult]) {
+		skipped[name] = true
+	}
+
+	var skippedTasks []v1beta1.SkippedTask
+	for _, task := range skippableTasks {
+		if skipped[task.Name] {
+			replacements := map[string]string{}
+			for _, we := range task.WhenExpressions {
+				expressions, _ := we.GetVarSubstitutionExpressions()
+				for _, ref := range v1beta1.NewResultRefs(expressions) {
+					if value, ok := values[namespaceName(ref.PipelineTask, ref.Result)]; ok {
+						replacements[fmt.Sprintf("tasks.%s.results.%s", ref.PipelineTask, ref.Result)] = value
+					}
+				}
+			}
+			skippedTasks = append(skippedTasks, v1beta1.SkippedTask{
+				Name:            task.Name,
+				WhenExpressions: v1beta1.WhenExpressions(task.WhenExpressions).ReplaceWhenExpressionsVariables(replacements),
+			})
+		}
+	}
+	return skippedTasks
+}
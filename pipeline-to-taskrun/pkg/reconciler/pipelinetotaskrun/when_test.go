@@ -0,0 +1,132 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinetotaskrun
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/experimental/pipeline-to-taskrun/test"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/test/diff"
+)
+
+func TestGetSkipCondition(t *testing.T) {
+	for _, tc := range []struct {
+		Name      string
+		Task      string
+		Skippable map[string]bool
+		Expected  string
+	}{{
+		Name: "no when expressions",
+		Task: `
+  - name: run-tests
+    params:
+    - name: revision
+      value: $(tasks.grab-source.results.commit)
+`,
+		Expected: "",
+	}, {
+		Name: "in and notin",
+		Task: `
+  - name: run-tests
+    when:
+    - input: "main"
+      operator: in
+      values: ["main", "it's release"]
+    - input: "$(tasks.grab-source.results.url)"
+      operator: notin
+      values: [""]
+`,
+		Expected: `! { [ 'main' = 'main' ] || [ 'main' = 'it'\''s release' ]; } || { [ "$(cat /tekton/results/grab-source-url)" = '' ]; }`,
+	}, {
+		Name: "uses the results of a task that can be skipped",
+		Task: `
+  - name: run-tests
+    params:
+    - name: revision
+      value: $(tasks.grab-source.results.commit)
+    when:
+    - input: "v$(tasks.check.results.version)-rc"
+      operator: in
+      values: ["v1-rc"]
+`,
+		Skippable: map[string]bool{"grab-source": true},
+		Expected:  `grep -qxF grab-source /tekton/results/pipeline-to-taskrun-skipped-tasks 2>/dev/null || ! { [ 'v'"$(cat /tekton/results/check-version)"'-rc' = 'v1-rc' ]; }`,
+	}, {
+		Name: "runs after a task that can be skipped",
+		Task: `
+  - name: run-tests
+    runAfter: [lint, grab-source]
+`,
+		Skippable: map[string]bool{"grab-source": true},
+		Expected:  `grep -qxF grab-source /tekton/results/pipeline-to-taskrun-skipped-tasks 2>/dev/null`,
+	}} {
+		t.Run(tc.Name, func(t *testing.T) {
+			pTask := test.MustParsePipeline(t, `
+spec:
+  tasks:
+`+tc.Task).Spec.Tasks[0]
+			condition := getSkipCondition(pTask.WhenExpressions, pTask.Deps(), tc.Skippable)
+			if d := cmp.Diff(tc.Expected, condition); d != "" {
+				t.Errorf("didn't get expected skip condition. Diff: %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestGetSkippedTasks(t *testing.T) {
+	skippableTasks := []v1beta1.SkippedTask{{
+		Name: "release",
+		WhenExpressions: v1beta1.WhenExpressions{{
+			Input:    "$(tasks.grab-source.results.branch)",
+			Operator: "in",
+			Values:   []string{"main"},
+		}},
+	}, {
+		Name: "deploy",
+		WhenExpressions: v1beta1.WhenExpressions{{
+			Input:    "staging",
+			Operator: "in",
+			Values:   []string{"staging", "prod"},
+		}},
+	}, {
+		Name: "notify",
+	}}
+	taskRunResults := []v1beta1.TaskRunResult{{
+		Name:  "grab-source-branch",
+		Value: "feature",
+	}, {
+		Name:  skippedTasksResult,
+		Value: "release\nnotify\n",
+	}}
+	expected := []v1beta1.SkippedTask{{
+		Name: "release",
+		WhenExpressions: v1beta1.WhenExpressions{{
+			Input:    "feature",
+			Operator: "in",
+			Values:   []string{"main"},
+		}},
+	}, {
+		Name: "notify",
+	}}
+
+	skippedTasks := getSkippedTasks(skippableTasks, taskRunResults)
+	if d := cmp.Diff(expected, skippedTasks); d != "" {
+		t.Errorf("didn't get expected skipped tasks. Diff: %s", diff.PrintWantGot(d))
+	}
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinetotaskrun
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+)
+
+const (
+	// wrapperDir is the directory in which wrapped steps place the scripts they run and record failures. It is under
+	// the home directory, which is shared by all the steps of a TaskRun.
+	wrapperDir = "/tekton/home/.pipeline-to-taskrun"
+
+	// failuresFile is the file in which wrapped steps record the names of the steps that failed
+	failuresFile = wrapperDir + "/failures"
+
+	// wrappedScriptDelimiter is the heredoc delimiter used to place the script of a wrapped step
+	wrappedScriptDelimiter = "PIPELINE_TO_TASKRUN_WRAPPED_SCRIPT"
+
+	// defaultScriptPreamble is added by Tekton to scripts without a shebang; wrapped scripts need it too since they
+	// are no longer run by Tekton directly
+	defaultScriptPreamble = "#!/bin/sh\nset -xe\n"
+)
+
+// StepWrapping describes how the steps of a pipeline task are wrapped in a shell script, which lets them be skipped
+// and lets their failures be recorded instead of stopping the TaskRun
+type StepWrapping struct {
+	// SkipCondition is a shell condition which is true if the pipeline task must be skipped, for example because its
+	// when expressions are false. The skipped task is recorded in the skippedTasksResult of the TaskRun.
+	SkipCondition string
+
+	// RecordFailures records a failure of a step instead of failing the step so that the steps after it still run,
+	// which is needed for the steps of finally tasks
+	RecordFailures bool
+
+	// SkipAfterFailure skips the steps once an earlier step has failed, which is the case for regular tasks but not
+	// finally tasks
+	SkipAfterFailure bool
+
+	// ReportFailures fails the last step if any step failed so that the TaskRun still reflects the failure
+	ReportFailures bool
+}
+
+// WrapSteps will return a new PipelineTaskInfo in which each step runs inside a shell wrapper as described by
+// wrapping. If wrapping is empty the steps are left as they are. Expects the steps to have been namespaced.
+func (pti PipelineTaskInfo) WrapSteps(wrapping StepWrapping) (PipelineTaskInfo, error) {
+	if wrapping == (StepWrapping{}) {
+		return pti, nil
+	}
+	updatedPti := PipelineTaskInfo{
+		Name:                pti.Name,
+		TaskDeclaredParams:  pti.TaskDeclaredParams,
+		ProvidedParamValues: pti.ProvidedParamValues,
+		Results:             pti.Results,
+	}
+
+	for i, step := range pti.Steps {
+		// unnamed steps still need a name to record in the failures and to place their script
+		name := step.Name
+		if name == "" {
+			name = getStepName(pti.Name, fmt.Sprintf("%d", i))
+		}
+		stepWrapping := wrapping
+		// only the last step reports the failures of all the steps
+		stepWrapping.ReportFailures = wrapping.ReportFailures && i == len(pti.Steps)-1
+		// every step is skipped but the task only needs to be recorded as skipped once
+		recordSkip := i == 0
+		wrappedStep, err := wrapStep(step, name, pti.Name, stepWrapping, recordSkip)
+		if err != nil {
+			return PipelineTaskInfo{}, err
+		}
+		updatedPti.Steps = append(updatedPti.Steps, wrappedStep)
+	}
+	return updatedPti, nil
+}
+
+// wrapStep will return a step which runs step via a shell script as described by wrapping. If recordSkip is true and
+// the step is skipped because of wrapping.SkipCondition, ptaskName is recorded as a skipped task.
+func wrapStep(step v1beta1.Step, name, ptaskName string, wrapping StepWrapping, recordSkip bool) (v1beta1.Step, error) {
+	var wrapper strings.Builder
+	wrapper.WriteString("#!/bin/sh\n")
+	fmt.Fprintf(&wrapper, "mkdir -p %s\n", wrapperDir)
+
+	var run []string
+	switch {
+	case step.Script != "":
+		script := step.Script
+		if !strings.HasPrefix(strings.TrimSpace(script), "#!") {
+			script = defaultScriptPreamble + script
+		}
+		scriptFile := fmt.Sprintf("%s/%s", wrapperDir, name)
+		fmt.Fprintf(&wrapper, "cat > %s << '%s'\n%s\n%s\n", scriptFile, wrappedScriptDelimiter, script, wrappedScriptDelimiter)
+		fmt.Fprintf(&wrapper, "chmod +x %s\n", scriptFile)
+		run = append(run, scriptFile)
+	case len(step.Command) > 0:
+		run = append(run, shellQuoteAll(step.Command)...)
+	default:
+		return step, fmt.Errorf("step %s needs a script or a command so that it can be wrapped", name)
+	}
+	run = append(run, shellQuoteAll(step.Args)...)
+
+	// each reason to skip the step is a branch of an if statement, followed by running the step
+	keyword := "if"
+	if wrapping.SkipAfterFailure {
+		fmt.Fprintf(&wrapper, "%s [ -f %s ]; then\n", keyword, failuresFile)
+		fmt.Fprintf(&wrapper, "  echo \"Skipping step %s because an earlier step failed\"\n", name)
+		keyword = "elif"
+	}
+	if wrapping.SkipCondition != "" {
+		fmt.Fprintf(&wrapper, "%s %s; then\n", keyword, wrapping.SkipCondition)
+		fmt.Fprintf(&wrapper, "  echo \"Skipping step %s because task %s was skipped\"\n", name, ptaskName)
+		if recordSkip {
+			fmt.Fprintf(&wrapper, "  echo %s >> %s/%s\n", ptaskName, resultsDir, skippedTasksResult)
+		}
+		keyword = "elif"
+	}
+	if wrapping.RecordFailures {
+		fmt.Fprintf(&wrapper, "%s ! %s; then\n", keyword, strings.Join(run, " "))
+		fmt.Fprintf(&wrapper, "  echo %s >> %s\n", name, failuresFile)
+		wrapper.WriteString("fi\n")
+	} else if keyword == "elif" {
+		fmt.Fprintf(&wrapper, "else\n  exec %s\n", strings.Join(run, " "))
+		wrapper.WriteString("fi\n")
+	} else {
+		fmt.Fprintf(&wrapper, "exec %s\n", strings.Join(run, " "))
+	}
+
+	if wrapping.ReportFailures {
+		fmt.Fprintf(&wrapper, "if [ -f %s ]; then\n", failuresFile)
+		wrapper.WriteString("  echo \"These steps failed:\"\n")
+		fmt.Fprintf(&wrapper, "  cat %s\n", failuresFile)
+		wrapper.WriteString("  exit 1\n")
+		wrapper.WriteString("fi\n")
+	}
+
+	wrappedStep := step.DeepCopy()
+	wrappedStep.Script = wrapper.String()
+	wrappedStep.Command = nil
+	wrappedStep.Args = nil
+	return *wrappedStep, nil
+}
+
+// shellQuoteAll returns each of the words quoted so that a shell passes it on unchanged
+func shellQuoteAll(words []string) []string {
+	var quoted []string
+	for _, w := range words {
+		quoted = append(quoted, "'"+strings.ReplaceAll(w, "'", `'\''`)+"'")
+	}
+	return quoted
+}
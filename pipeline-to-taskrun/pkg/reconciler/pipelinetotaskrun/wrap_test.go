@@ -0,0 +1,162 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinetotaskrun
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/test/diff"
+)
+
+func TestWrapSteps(t *testing.T) {
+	steps := `
+  - name: run-tests-unit-test
+    image: golang
+    script: |
+      go test ./...
+  - image: ubuntu
+    command: ["echo"]
+    args: ["it's done"]
+`
+	for _, tc := range []struct {
+		Name          string
+		Wrapping      StepWrapping
+		ExpectedSteps string
+	}{{
+		Name:     "regular task",
+		Wrapping: StepWrapping{RecordFailures: true, SkipAfterFailure: true},
+		ExpectedSteps: `
+  - name: run-tests-unit-test
+    image: golang
+    script: |
+      #!/bin/sh
+      mkdir -p /tekton/home/.pipeline-to-taskrun
+      cat > /tekton/home/.pipeline-to-taskrun/run-tests-unit-test << 'PIPELINE_TO_TASKRUN_WRAPPED_SCRIPT'
+      #!/bin/sh
+      set -xe
+      go test ./...
+
+      PIPELINE_TO_TASKRUN_WRAPPED_SCRIPT
+      chmod +x /tekton/home/.pipeline-to-taskrun/run-tests-unit-test
+      if [ -f /tekton/home/.pipeline-to-taskrun/failures ]; then
+        echo "Skipping step run-tests-unit-test because an earlier step failed"
+      elif ! /tekton/home/.pipeline-to-taskrun/run-tests-unit-test; then
+        echo run-tests-unit-test >> /tekton/home/.pipeline-to-taskrun/failures
+      fi
+  - image: ubuntu
+    script: |
+      #!/bin/sh
+      mkdir -p /tekton/home/.pipeline-to-taskrun
+      if [ -f /tekton/home/.pipeline-to-taskrun/failures ]; then
+        echo "Skipping step run-tests-1 because an earlier step failed"
+      elif ! 'echo' 'it'\''s done'; then
+        echo run-tests-1 >> /tekton/home/.pipeline-to-taskrun/failures
+      fi
+`,
+	}, {
+		Name:     "last finally task",
+		Wrapping: StepWrapping{RecordFailures: true, ReportFailures: true},
+		ExpectedSteps: `
+  - name: run-tests-unit-test
+    image: golang
+    script: |
+      #!/bin/sh
+      mkdir -p /tekton/home/.pipeline-to-taskrun
+      cat > /tekton/home/.pipeline-to-taskrun/run-tests-unit-test << 'PIPELINE_TO_TASKRUN_WRAPPED_SCRIPT'
+      #!/bin/sh
+      set -xe
+      go test ./...
+
+      PIPELINE_TO_TASKRUN_WRAPPED_SCRIPT
+      chmod +x /tekton/home/.pipeline-to-taskrun/run-tests-unit-test
+      if ! /tekton/home/.pipeline-to-taskrun/run-tests-unit-test; then
+        echo run-tests-unit-test >> /tekton/home/.pipeline-to-taskrun/failures
+      fi
+  - image: ubuntu
+    script: |
+      #!/bin/sh
+      mkdir -p /tekton/home/.pipeline-to-taskrun
+      if ! 'echo' 'it'\''s done'; then
+        echo run-tests-1 >> /tekton/home/.pipeline-to-taskrun/failures
+      fi
+      if [ -f /tekton/home/.pipeline-to-taskrun/failures ]; then
+        echo "These steps failed:"
+        cat /tekton/home/.pipeline-to-taskrun/failures
+        exit 1
+      fi
+`,
+	}, {
+		Name:     "task that can be skipped",
+		Wrapping: StepWrapping{SkipCondition: "! { [ 'yes' = 'yes' ]; }"},
+		ExpectedSteps: `
+  - name: run-tests-unit-test
+    image: golang
+    script: |
+      #!/bin/sh
+      mkdir -p /tekton/home/.pipeline-to-taskrun
+      cat > /tekton/home/.pipeline-to-taskrun/run-tests-unit-test << 'PIPELINE_TO_TASKRUN_WRAPPED_SCRIPT'
+      #!/bin/sh
+      set -xe
+      go test ./...
+
+      PIPELINE_TO_TASKRUN_WRAPPED_SCRIPT
+      chmod +x /tekton/home/.pipeline-to-taskrun/run-tests-unit-test
+      if ! { [ 'yes' = 'yes' ]; }; then
+        echo "Skipping step run-tests-unit-test because task run-tests was skipped"
+        echo run-tests >> /tekton/results/pipeline-to-taskrun-skipped-tasks
+      else
+        exec /tekton/home/.pipeline-to-taskrun/run-tests-unit-test
+      fi
+  - image: ubuntu
+    script: |
+      #!/bin/sh
+      mkdir -p /tekton/home/.pipeline-to-taskrun
+      if ! { [ 'yes' = 'yes' ]; }; then
+        echo "Skipping step run-tests-1 because task run-tests was skipped"
+      else
+        exec 'echo' 'it'\''s done'
+      fi
+`,
+	}, {
+		Name:          "nothing to wrap",
+		ExpectedSteps: steps,
+	}} {
+		t.Run(tc.Name, func(t *testing.T) {
+			pti := parsePipelineTaskInfo(t, "run-tests", ``, ``, steps, ``)
+			expected := parsePipelineTaskInfo(t, "run-tests", ``, ``, tc.ExpectedSteps, ``)
+			updatedPti, err := pti.WrapSteps(tc.Wrapping)
+			if err != nil {
+				t.Fatalf("didn't expect error wrapping steps but got %v", err)
+			}
+			if d := cmp.Diff(expected, updatedPti); d != "" {
+				t.Errorf("didn't get expected updated info. Diff: %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestWrapStepsWithoutScriptOrCommand(t *testing.T) {
+	pti := parsePipelineTaskInfo(t, "notify", ``, ``, `
+  - name: notify-send
+    image: some-notifier
+    args: ["--channel", "builds"]
+`, ``)
+	if _, err := pti.WrapSteps(StepWrapping{RecordFailures: true, ReportFailures: true}); err == nil {
+		t.Errorf("expected error wrapping a step that only uses the entrypoint of its image but got none")
+	}
+}
@@ -48,6 +48,9 @@ const (
 	// ReasonRunFailedCreatingPipelineRun indicates that the reason for failure status is that Run failed
 	// to create PipelineRun
 	ReasonRunFailedCreatingPipelineRun = "ReasonRunFailedCreatingPipelineRun"
+
+	// ReasonRunCancelled indicates that the reason for failure status is that Run was cancelled
+	ReasonRunCancelled = "ReasonRunCancelled"
 )
 
 // Reconciler implements controller.Reconciler for Run resources.
@@ -126,9 +129,22 @@ func (r *Reconciler) reconcile(ctx context.Context, run *v1alpha1.Run) error {
 
 	// fetch the pipelinerun and, if present, update the run status
 	if pr := r.getPipelineRun(ctx, run); pr != nil {
+		// cancel the pipelinerun when the run is cancelled, the run fails once the pipelinerun stops
+		if run.IsCancelled() && !pr.IsDone() && !pr.IsCancelled() {
+			if err := r.cancelPipelineRun(ctx, pr); err != nil {
+				logger.Errorf("Run %s/%s got an error cancelling PipelineRun - %v", run.Namespace, run.Name, err)
+				return err
+			}
+		}
 		return updateRunStatus(ctx, run, pr)
 	}
 
+	// the run was cancelled before its pipelinerun was created
+	if run.IsCancelled() {
+		run.Status.MarkRunFailed(ReasonRunCancelled, "Run %s/%s was cancelled", run.Namespace, run.Name)
+		return nil
+	}
+
 	// pipelinerun doesn't exist yet, create a new pipelinerun
 	if _, err := r.createPipelineRun(ctx, run); err != nil {
 		logger.Errorf("Run %s/%s got an error creating PipelineRun - %v", run.Namespace, run.Name, err)
@@ -142,11 +158,20 @@ func (r *Reconciler) reconcile(ctx context.Context, run *v1alpha1.Run) error {
 func updateRunStatus(ctx context.Context, run *v1alpha1.Run, pipelineRun *v1beta1.PipelineRun) error {
 	logger := logging.FromContext(ctx)
 
+	// record the status of the tasks of the pipelinerun so that failures can be traced to a task
+	if err := run.Status.EncodeExtraFields(getPipelineRunStatus(pipelineRun)); err != nil {
+		logger.Errorf("Run %s/%s got an error recording the status of PipelineRun %s - %v", run.Namespace, run.Name, pipelineRun.Name, err)
+		return err
+	}
+
 	c := pipelineRun.GetStatusCondition().GetCondition(apis.ConditionSucceeded)
 	if c.IsTrue() {
 		logger.Infof("PipelineRun created by Run %s/%s has succeeded", run.Namespace, run.Name)
 		run.Status.MarkRunSucceeded(c.Reason, c.Message)
 		propagateResults(run, pipelineRun)
+	} else if c.IsFalse() && run.IsCancelled() {
+		logger.Infof("PipelineRun created by Run %s/%s has stopped after the Run was cancelled", run.Namespace, run.Name)
+		run.Status.MarkRunFailed(ReasonRunCancelled, "Run %s/%s was cancelled - %s", run.Namespace, run.Name, c.Message)
 	} else if c.IsFalse() {
 		logger.Infof("PipelineRun created by Run %s/%s has failed", run.Namespace, run.Name)
 		run.Status.MarkRunFailed(c.Reason, c.Message)
@@ -203,6 +228,24 @@ func (r *Reconciler) createPipelineRun(ctx context.Context, run *v1alpha1.Run) (
 	return r.pipelineClientSet.TektonV1beta1().PipelineRuns(run.Namespace).Create(ctx, pr, metav1.CreateOptions{})
 }
 
+func (r *Reconciler) cancelPipelineRun(ctx context.Context, pr *v1beta1.PipelineRun) error {
+	logger := logging.FromContext(ctx)
+
+	mergePatch := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"status": v1beta1.PipelineRunSpecStatusCancelled,
+		},
+	}
+	patch, err := json.Marshal(mergePatch)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("Cancelling PipelineRun object %s", pr.Name)
+	_, err = r.pipelineClientSet.TektonV1beta1().PipelineRuns(pr.Namespace).Patch(ctx, pr.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
 func getObjectMeta(run *v1alpha1.Run) metav1.ObjectMeta {
 	return metav1.ObjectMeta{
 		Name:            run.Name,
@@ -227,6 +270,7 @@ func getPipelineRef(run *v1alpha1.Run) *v1beta1.PipelineRef {
 	return &v1beta1.PipelineRef{
 		Name:       run.Spec.Ref.Name,
 		APIVersion: pipeline.GroupName,
+		Bundle:     run.Spec.Ref.Bundle,
 	}
 }
 
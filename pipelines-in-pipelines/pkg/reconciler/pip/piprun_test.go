@@ -27,6 +27,7 @@ import (
 	"github.com/tektoncd/experimental/pipelines-in-pipelines/test"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	runv1alpha1 "github.com/tektoncd/pipeline/pkg/apis/run/v1alpha1"
 	ttesting "github.com/tektoncd/pipeline/pkg/reconciler/testing"
 	"github.com/tektoncd/pipeline/test/diff"
 	"github.com/tektoncd/pipeline/test/names"
@@ -37,6 +38,7 @@ import (
 	ktesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/record"
 	"knative.dev/pkg/apis"
+	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/logging"
@@ -170,6 +172,24 @@ func failed(pr *v1beta1.PipelineRun) *v1beta1.PipelineRun {
 	return prWithStatus
 }
 
+func cancelled(pr *v1beta1.PipelineRun) *v1beta1.PipelineRun {
+	prWithStatus := pr.DeepCopy()
+	prWithStatus.Spec.Status = v1beta1.PipelineRunSpecStatusCancelled
+	prWithStatus.Status.SetCondition(&apis.Condition{
+		Type:    apis.ConditionSucceeded,
+		Status:  corev1.ConditionFalse,
+		Reason:  v1beta1.PipelineRunReasonCancelled.String(),
+		Message: "PipelineRun \"run-with-pipeline\" was cancelled",
+	})
+	return prWithStatus
+}
+
+func requestCancel(run *v1alpha1.Run) *v1alpha1.Run {
+	runWithCancelStatus := run.DeepCopy()
+	runWithCancelStatus.Spec.Status = v1alpha1.RunSpecStatusCancelled
+	return runWithCancelStatus
+}
+
 func withResults(pr *v1beta1.PipelineRun, name string, value string) *v1beta1.PipelineRun {
 	prWithStatus := pr.DeepCopy()
 	prWithStatus.Status.PipelineResults = append(prWithStatus.Status.PipelineResults, v1beta1.PipelineRunResult{
@@ -242,6 +262,27 @@ var runWithPipeline = &v1alpha1.Run{
 	},
 }
 
+var runWithBundle = &v1alpha1.Run{
+	ObjectMeta: metav1.ObjectMeta{
+		Name:      "run-with-pipeline",
+		Namespace: "foo",
+	},
+	Spec: v1alpha1.RunSpec{
+		Ref: &v1alpha1.TaskRef{
+			APIVersion: "tekton.dev/v1beta1",
+			Kind:       "Pipeline",
+			Name:       "pipeline",
+			Bundle:     "gcr.io/foo/bar:latest",
+		},
+	},
+}
+
+func withBundle(pr *v1beta1.PipelineRun, bundle string) *v1beta1.PipelineRun {
+	prWithBundle := pr.DeepCopy()
+	prWithBundle.Spec.PipelineRef.Bundle = bundle
+	return prWithBundle
+}
+
 var runWithoutPipelineName = &v1alpha1.Run{
 	ObjectMeta: metav1.ObjectMeta{
 		Name:      "run-with-missing-pipeline",
@@ -303,6 +344,16 @@ func TestReconcilePipRun(t *testing.T) {
 		expectedEvents: []string{
 			"Normal Started ",
 		},
+	}, {
+		name:                "Reconcile a new run that references a pipeline in a bundle",
+		pipeline:            p,
+		run:                 runWithBundle,
+		expectedPipelineRun: withBundle(pr, "gcr.io/foo/bar:latest"),
+		expectedStatus:      corev1.ConditionUnknown,
+		expectedReason:      v1beta1.PipelineRunReasonStarted,
+		expectedEvents: []string{
+			"Normal Started ",
+		},
 	}, {
 		name:           "Reconcile a run with a running PipelineRun",
 		pipeline:       p,
@@ -415,3 +466,230 @@ func TestReconcilePipRun(t *testing.T) {
 		})
 	}
 }
+
+func TestReconcilePipRunCancellation(t *testing.T) {
+	testcases := []struct {
+		name            string
+		pipelineRun     *v1beta1.PipelineRun
+		expectedStatus  corev1.ConditionStatus
+		expectedReason  string
+		expectedMessage string
+		expectedPatch   bool
+		expectedEvents  []string
+	}{{
+		name:           "Cancel the running PipelineRun of a cancelled run",
+		pipelineRun:    running(pr),
+		expectedStatus: corev1.ConditionUnknown,
+		expectedReason: v1beta1.PipelineRunReasonRunning.String(),
+		expectedPatch:  true,
+		expectedEvents: []string{
+			"Normal Started ",
+			"Normal Running ",
+		},
+	}, {
+		name:            "Fail a cancelled run once its PipelineRun is cancelled",
+		pipelineRun:     cancelled(pr),
+		expectedStatus:  corev1.ConditionFalse,
+		expectedReason:  ReasonRunCancelled,
+		expectedMessage: `Run foo/run-with-pipeline was cancelled - PipelineRun "run-with-pipeline" was cancelled`,
+		expectedEvents: []string{
+			"Normal Started ",
+			`Warning Failed Run foo/run-with-pipeline was cancelled - PipelineRun "run-with-pipeline" was cancelled`,
+		},
+	}, {
+		name:            "Fail a cancelled run that has no PipelineRun yet",
+		expectedStatus:  corev1.ConditionFalse,
+		expectedReason:  ReasonRunCancelled,
+		expectedMessage: "Run foo/run-with-pipeline was cancelled",
+		expectedEvents: []string{
+			"Normal Started ",
+			"Warning Failed Run foo/run-with-pipeline was cancelled",
+		},
+	}, {
+		name:           "Don't cancel a PipelineRun that has already succeeded",
+		pipelineRun:    successful(pr),
+		expectedStatus: corev1.ConditionTrue,
+		expectedReason: v1beta1.PipelineRunReasonSuccessful.String(),
+		expectedEvents: []string{
+			"Normal Started ",
+			"Normal Succeeded ",
+		},
+	}}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			names.TestingSeed()
+
+			optionalPipelineRuns := []*v1beta1.PipelineRun{tc.pipelineRun}
+			if tc.pipelineRun == nil {
+				optionalPipelineRuns = nil
+			}
+
+			cancelledRun := requestCancel(runWithPipeline)
+			d := test.Data{
+				Runs:         []*v1alpha1.Run{cancelledRun},
+				Pipelines:    []*v1beta1.Pipeline{p},
+				PipelineRuns: optionalPipelineRuns,
+			}
+
+			testAssets, _ := getPipController(t, d)
+			c := testAssets.Controller
+			clients := testAssets.Clients
+
+			c.Reconciler.Reconcile(ctx, getRunName(cancelledRun))
+
+			run, err := clients.Pipeline.TektonV1alpha1().Runs(cancelledRun.Namespace).Get(ctx, cancelledRun.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Error getting reconciled run from fake client: %s", err)
+			}
+
+			if createdPipelineRun := getCreatedPipelineRun(clients); createdPipelineRun != nil {
+				t.Errorf("A PipelineRun should not have been created for a cancelled run but %s was", createdPipelineRun.Name)
+			}
+
+			var patched bool
+			for _, a := range clients.Pipeline.Actions() {
+				if a.GetVerb() == "patch" && a.GetResource().Resource == "pipelineruns" {
+					patched = true
+					if d := cmp.Diff(`{"spec":{"status":"PipelineRunCancelled"}}`, string(a.(ktesting.PatchAction).GetPatch())); d != "" {
+						t.Errorf("PipelineRun was patched incorrectly. Diff %s", diff.PrintWantGot(d))
+					}
+				}
+			}
+			if patched != tc.expectedPatch {
+				t.Errorf("Expected PipelineRun to be cancelled: %t but it was: %t", tc.expectedPatch, patched)
+			}
+
+			checkRunCondition(t, run, tc.expectedStatus, tc.expectedReason, tc.expectedMessage)
+
+			if err := checkEvents(testAssets.Recorder, tc.name, tc.expectedEvents); err != nil {
+				t.Errorf(err.Error())
+			}
+		})
+	}
+}
+
+func TestReconcilePipRunTaskStatuses(t *testing.T) {
+	ctx := context.Background()
+	names.TestingSeed()
+
+	nestedStatus := &runv1alpha1.RunStatus{}
+	nestedStatus.SetCondition(&apis.Condition{
+		Type:   apis.ConditionSucceeded,
+		Status: corev1.ConditionFalse,
+		Reason: v1beta1.PipelineRunReasonFailed.String(),
+	})
+	if err := nestedStatus.EncodeExtraFields(&PipelineRunStatus{
+		PipelineRunName: "run-with-pipeline-nested",
+		Tasks: []TaskStatus{{
+			PipelineTaskName: "unit-tests",
+			TaskRunName:      "run-with-pipeline-nested-unit-tests",
+			Reason:           v1beta1.TaskRunReasonFailed.String(),
+			FailedStep:       "test",
+		}},
+	}); err != nil {
+		t.Fatalf("EncodeExtraFields error: %v", err)
+	}
+
+	pipelineRun := failed(pr)
+	pipelineRun.Status.PipelineSpec = &v1beta1.PipelineSpec{
+		Tasks: []v1beta1.PipelineTask{{
+			Name:    "build",
+			TaskRef: &v1beta1.TaskRef{Name: "build"},
+		}, {
+			Name:    "lint",
+			TaskRef: &v1beta1.TaskRef{Name: "lint"},
+		}, {
+			Name:    "nested",
+			TaskRef: &v1beta1.TaskRef{APIVersion: "tekton.dev/v1beta1", Kind: "Pipeline", Name: "nested"},
+		}},
+	}
+	pipelineRun.Status.TaskRuns = map[string]*v1beta1.PipelineRunTaskRunStatus{
+		"run-with-pipeline-lint": {
+			PipelineTaskName: "lint",
+			Status: &v1beta1.TaskRunStatus{
+				Status: duckv1beta1.Status{Conditions: duckv1beta1.Conditions{{
+					Type:   apis.ConditionSucceeded,
+					Status: corev1.ConditionFalse,
+					Reason: v1beta1.TaskRunReasonFailed.String(),
+				}}},
+				TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+					Steps: []v1beta1.StepState{{
+						Name:           "fetch",
+						ContainerState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0}},
+					}, {
+						Name:           "golangci-lint",
+						ContainerState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1}},
+					}},
+				},
+			},
+		},
+		"run-with-pipeline-build": {
+			PipelineTaskName: "build",
+			Status: &v1beta1.TaskRunStatus{
+				Status: duckv1beta1.Status{Conditions: duckv1beta1.Conditions{{
+					Type:   apis.ConditionSucceeded,
+					Status: corev1.ConditionTrue,
+					Reason: v1beta1.TaskRunReasonSuccessful.String(),
+				}}},
+			},
+		},
+	}
+	pipelineRun.Status.Runs = map[string]*v1beta1.PipelineRunRunStatus{
+		"run-with-pipeline-nested": {
+			PipelineTaskName: "nested",
+			Status:           nestedStatus,
+		},
+	}
+
+	d := test.Data{
+		Runs:         []*v1alpha1.Run{runWithPipeline},
+		Pipelines:    []*v1beta1.Pipeline{p},
+		PipelineRuns: []*v1beta1.PipelineRun{pipelineRun},
+	}
+
+	testAssets, _ := getPipController(t, d)
+	c := testAssets.Controller
+	clients := testAssets.Clients
+
+	c.Reconciler.Reconcile(ctx, getRunName(runWithPipeline))
+
+	run, err := clients.Pipeline.TektonV1alpha1().Runs(runWithPipeline.Namespace).Get(ctx, runWithPipeline.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Error getting reconciled run from fake client: %s", err)
+	}
+
+	status := &PipelineRunStatus{}
+	if err := run.Status.DecodeExtraFields(status); err != nil {
+		t.Fatalf("DecodeExtraFields error: %v", err)
+	}
+	expectedStatus := &PipelineRunStatus{
+		PipelineRunName: "run-with-pipeline",
+		Tasks: []TaskStatus{{
+			PipelineTaskName: "build",
+			TaskRunName:      "run-with-pipeline-build",
+			Reason:           v1beta1.TaskRunReasonSuccessful.String(),
+		}, {
+			PipelineTaskName: "lint",
+			TaskRunName:      "run-with-pipeline-lint",
+			Reason:           v1beta1.TaskRunReasonFailed.String(),
+			FailedStep:       "golangci-lint",
+		}, {
+			PipelineTaskName: "nested",
+			RunName:          "run-with-pipeline-nested",
+			Reason:           v1beta1.PipelineRunReasonFailed.String(),
+			PipelineRun: &PipelineRunStatus{
+				PipelineRunName: "run-with-pipeline-nested",
+				Tasks: []TaskStatus{{
+					PipelineTaskName: "unit-tests",
+					TaskRunName:      "run-with-pipeline-nested-unit-tests",
+					Reason:           v1beta1.TaskRunReasonFailed.String(),
+					FailedStep:       "test",
+				}},
+			},
+		}},
+	}
+	if d := cmp.Diff(expectedStatus, status); d != "" {
+		t.Errorf("Run status has the wrong task statuses. Diff %s", diff.PrintWantGot(d))
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pip
+
+import (
+	"sort"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"knative.dev/pkg/apis"
+)
+
+// PipelineRunStatus is a compact status of the PipelineRun created for a Run, recorded in the ExtraFields of the Run
+type PipelineRunStatus struct {
+	// PipelineRunName is the name of the PipelineRun
+	PipelineRunName string `json:"pipelineRunName"`
+
+	// Tasks are the statuses of the tasks of the PipelineRun, ordered by task name
+	// +optional
+	Tasks []TaskStatus `json:"tasks,omitempty"`
+}
+
+// TaskStatus is the status of a task of a PipelineRun
+type TaskStatus struct {
+	// PipelineTaskName is the name of the task in the Pipeline
+	PipelineTaskName string `json:"pipelineTaskName"`
+
+	// TaskRunName is the name of the TaskRun of the task, if it runs a Task
+	// +optional
+	TaskRunName string `json:"taskRunName,omitempty"`
+
+	// RunName is the name of the Run of the task, if it runs a Custom Task
+	// +optional
+	RunName string `json:"runName,omitempty"`
+
+	// Reason is the reason of the ConditionSucceeded of the TaskRun or Run
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// FailedStep is the name of the first step of the TaskRun that failed
+	// +optional
+	FailedStep string `json:"failedStep,omitempty"`
+
+	// PipelineRun is the status of the nested PipelineRun, if the task runs a Pipeline in a Pipeline
+	// +optional
+	PipelineRun *PipelineRunStatus `json:"pipelineRun,omitempty"`
+}
+
+func getPipelineRunStatus(pipelineRun *v1beta1.PipelineRun) *PipelineRunStatus {
+	status := &PipelineRunStatus{PipelineRunName: pipelineRun.Name}
+
+	for name, tr := range pipelineRun.Status.TaskRuns {
+		task := TaskStatus{
+			PipelineTaskName: tr.PipelineTaskName,
+			TaskRunName:      name,
+		}
+		if tr.Status != nil {
+			if c := tr.Status.GetCondition(apis.ConditionSucceeded); c != nil {
+				task.Reason = c.Reason
+			}
+			task.FailedStep = getFailedStep(tr.Status)
+		}
+		status.Tasks = append(status.Tasks, task)
+	}
+
+	for name, r := range pipelineRun.Status.Runs {
+		task := TaskStatus{
+			PipelineTaskName: r.PipelineTaskName,
+			RunName:          name,
+		}
+		if r.Status != nil {
+			if c := r.Status.GetCondition(apis.ConditionSucceeded); c != nil {
+				task.Reason = c.Reason
+			}
+			// a nested pipeline in pipeline records its own tree in the ExtraFields of its Run
+			if isPipelineTask(pipelineRun, r.PipelineTaskName) {
+				nested := &PipelineRunStatus{}
+				if err := r.Status.DecodeExtraFields(nested); err == nil && nested.PipelineRunName != "" {
+					task.PipelineRun = nested
+				}
+			}
+		}
+		status.Tasks = append(status.Tasks, task)
+	}
+
+	sort.Slice(status.Tasks, func(i, j int) bool {
+		if status.Tasks[i].PipelineTaskName != status.Tasks[j].PipelineTaskName {
+			return status.Tasks[i].PipelineTaskName < status.Tasks[j].PipelineTaskName
+		}
+		return status.Tasks[i].TaskRunName+status.Tasks[i].RunName < status.Tasks[j].TaskRunName+status.Tasks[j].RunName
+	})
+	return status
+}
+
+func getFailedStep(status *v1beta1.TaskRunStatus) string {
+	for _, step := range status.Steps {
+		if step.Terminated != nil && step.Terminated.ExitCode != 0 {
+			return step.Name
+		}
+	}
+	return ""
+}
+
+func isPipelineTask(pipelineRun *v1beta1.PipelineRun, pipelineTaskName string) bool {
+	if pipelineRun.Status.PipelineSpec == nil {
+		return false
+	}
+	for _, task := range pipelineRun.Status.PipelineSpec.Tasks {
+		if task.Name == pipelineTaskName {
+			return task.TaskRef != nil && task.TaskRef.APIVersion == v1beta1.SchemeGroupVersion.String() && task.TaskRef.Kind == kind
+		}
+	}
+	return false
+}
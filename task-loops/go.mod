@@ -5,6 +5,7 @@ go 1.13
 require (
 	github.com/ghodss/yaml v1.0.0
 	github.com/google/go-cmp v0.5.4
+	github.com/google/go-containerregistry v0.2.1
 	github.com/hashicorp/go-multierror v1.1.0
 	github.com/tektoncd/pipeline v0.20.1
 	go.opencensus.io v0.22.5
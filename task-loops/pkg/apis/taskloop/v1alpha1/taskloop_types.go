@@ -17,8 +17,17 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
 	v1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // +genclient
@@ -48,21 +57,175 @@ type TaskLoopSpec struct {
 	TaskSpec *v1beta1.TaskSpec `json:"taskSpec,omitempty"`
 
 	// IterateParam is the name of the task parameter that is iterated upon.
-	IterateParam string `json:"iterateParam"`
+	// +optional
+	IterateParam string `json:"iterateParam,omitempty"`
+
+	// IterateParams are the names of the task parameters that are iterated upon.
+	// A TaskRun is created for each combination of their values.
+	// +optional
+	IterateParams []string `json:"iterateParams,omitempty"`
+
+	// IterateFields maps the fields of the JSON objects held by IterateParam onto task parameters.
+	// When it is set, the value of IterateParam must be a JSON array of objects.
+	// +optional
+	IterateFields []IterateField `json:"iterateFields,omitempty"`
 
-	// Time after which the TaskRun times out.
+	// IterateNumeric is a numeric range that is iterated upon.
+	// It can be used instead of or together with the iterate parameters.
+	// +optional
+	IterateNumeric *IterateNumeric `json:"iterateNumeric,omitempty"`
+
+	// IterationMode controls how the values of multiple iterate parameters are combined.
+	// Defaults to matrix.
+	// +optional
+	IterationMode IterationMode `json:"iterationMode,omitempty"`
+
+	// Time after which the TaskRun of each iteration times out.
 	// +optional
 	Timeout *metav1.Duration `json:"timeout,omitempty"`
 
+	// LoopTimeout is the time after which the whole Run times out.  When it passes, the TaskRuns that are
+	// still running are cancelled and no more iterations are started.  By default the Run doesn't time out.
+	// +optional
+	LoopTimeout *metav1.Duration `json:"loopTimeout,omitempty"`
+
 	// Retries represents how many times a task should be retried in case of task failure.
 	// +optional
 	Retries int `json:"retries,omitempty"`
 
+	// RetryBackoff delays the retries of a failed TaskRun.  By default a failed TaskRun is retried immediately.
+	// +optional
+	RetryBackoff *RetryBackoff `json:"retryBackoff,omitempty"`
+
 	// Concurrency represents how many tasks can be running at the same time.
 	// +optional
 	Concurrency *int `json:"concurrency,omitempty"`
+
+	// NamespaceConcurrency limits how many tasks can be running at the same time across the Runs of TaskLoops in the namespace.
+	// It applies in addition to Concurrency.
+	// +optional
+	NamespaceConcurrency *NamespaceConcurrency `json:"namespaceConcurrency,omitempty"`
+
+	// FailurePolicy determines whether TaskRuns continue to be created after a TaskRun fails.
+	// Defaults to failFast.
+	// +optional
+	FailurePolicy FailurePolicy `json:"failurePolicy,omitempty"`
+
+	// FailureThreshold is the number or percentage of failed iterations at which the Run fails.
+	// It is required by the threshold failure policy and not allowed otherwise.
+	// +optional
+	FailureThreshold *intstr.IntOrString `json:"failureThreshold,omitempty"`
+
+	// ResultsFormat determines how the results of the TaskRuns are published as Run results.
+	// Defaults to array.
+	// +optional
+	ResultsFormat ResultsFormat `json:"resultsFormat,omitempty"`
 }
 
+// IterateField maps a field of the JSON objects that are iterated upon onto a task parameter.
+type IterateField struct {
+	// Field is the name of the field in each JSON object.
+	Field string `json:"field"`
+
+	// Param is the name of the task parameter that receives the value of the field.
+	Param string `json:"param"`
+}
+
+// IterateNumeric describes a numeric range.  Each value is passed to a TaskRun in a task parameter.
+// The bounds and the step are either integers or a reference to a Run parameter, e.g. "$(params.shards)".
+type IterateNumeric struct {
+	// Param is the name of the task parameter that receives the current value of the range.
+	Param string `json:"param"`
+
+	// From is the first value of the range.  Defaults to 0.
+	// +optional
+	From string `json:"from,omitempty"`
+
+	// To is the last value of the range.  It is included in the range if it is reached by the step.
+	To string `json:"to"`
+
+	// Step is the difference between consecutive values of the range.  Defaults to 1.
+	// +optional
+	Step string `json:"step,omitempty"`
+}
+
+// MaxNumericIterations is the largest number of values that a numeric range may have.
+const MaxNumericIterations = 10000
+
+// RetryBackoff describes an exponentially increasing delay between the retries of a TaskRun.
+type RetryBackoff struct {
+	// InitialDelay is the delay between the failure of a TaskRun and its first retry.
+	InitialDelay *metav1.Duration `json:"initialDelay"`
+
+	// Multiplier is the factor by which the delay increases for each subsequent retry.  Defaults to 2.
+	// +optional
+	Multiplier int `json:"multiplier,omitempty"`
+
+	// MaxDelay is the upper limit of the delay.
+	// +optional
+	MaxDelay *metav1.Duration `json:"maxDelay,omitempty"`
+}
+
+// NamespaceConcurrency describes a limit on the number of TaskRuns that run at the same time across Runs.
+// Exactly one of Limit and ConfigMapKeyRef must be specified.
+type NamespaceConcurrency struct {
+	// LabelKey is the key of a Run label.  The limit is shared by the Runs that have the same value for the label.
+	// If it is not specified, the limit is shared by the Runs of all TaskLoops in the namespace.
+	// +optional
+	LabelKey string `json:"labelKey,omitempty"`
+
+	// Limit is the maximum number of TaskRuns that run at the same time.
+	// +optional
+	Limit *int `json:"limit,omitempty"`
+
+	// ConfigMapKeyRef selects the key of a ConfigMap in the namespace that holds the limit.
+	// +optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+}
+
+// IterationMode represents how the values of multiple iterate parameters are combined
+type IterationMode string
+
+const (
+	// IterationModeMatrix creates a TaskRun for each combination of the iterate parameter values
+	IterationModeMatrix IterationMode = "matrix"
+
+	// IterationModeZip creates a TaskRun for each position in the iterate parameter values,
+	// which must all have the same number of values
+	IterationModeZip IterationMode = "zip"
+)
+
+// FailurePolicy represents what happens to the remaining iterations when a TaskRun fails
+type FailurePolicy string
+
+const (
+	// FailurePolicyFailFast stops creating TaskRuns as soon as a TaskRun fails and fails the Run
+	FailurePolicyFailFast FailurePolicy = "failFast"
+
+	// FailurePolicyRunAll creates TaskRuns for all iterations and fails the Run if any of them failed
+	FailurePolicyRunAll FailurePolicy = "runAll"
+
+	// FailurePolicyThreshold stops creating TaskRuns and fails the Run once the number of failed
+	// iterations reaches the failure threshold
+	FailurePolicyThreshold FailurePolicy = "threshold"
+)
+
+// ResultsFormat represents how the results of the TaskRuns are published as Run results
+type ResultsFormat string
+
+const (
+	// ResultsFormatArray publishes each TaskRun result as a Run result of the same name whose value is
+	// a JSON array with one element per iteration, in iteration order
+	ResultsFormatArray ResultsFormat = "array"
+
+	// ResultsFormatPerIteration publishes each TaskRun result as one Run result per iteration,
+	// named <result name>-<iteration number>
+	ResultsFormatPerIteration ResultsFormat = "perIteration"
+
+	// FailedIterationsResultName is the name of the Run result that lists the failed iterations as a JSON array
+	FailedIterationsResultName = "failed-iterations"
+)
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // TaskLoopList contains a list of TaskLoops
@@ -93,9 +256,18 @@ const (
 	// the running TaskRun as cancelled failed.
 	TaskLoopRunReasonCouldntCancel TaskLoopRunReason = "TaskLoopRunCouldntCancel"
 
+	// TaskLoopRunReasonTimedOut indicates that the Run failed because its loop timeout passed
+	TaskLoopRunReasonTimedOut TaskLoopRunReason = "TaskLoopRunTimedOut"
+
 	// TaskLoopRunReasonCouldntGetTaskLoop indicates that the associated TaskLoop couldn't be retrieved
 	TaskLoopRunReasonCouldntGetTaskLoop TaskLoopRunReason = "CouldntGetTaskLoop"
 
+	// TaskLoopRunReasonCouldntGetTask indicates that the task referenced by the TaskLoop couldn't be retrieved
+	TaskLoopRunReasonCouldntGetTask TaskLoopRunReason = "CouldntGetTask"
+
+	// TaskLoopRunReasonCouldntGetConcurrencyLimit indicates that the namespace concurrency limit couldn't be retrieved
+	TaskLoopRunReasonCouldntGetConcurrencyLimit TaskLoopRunReason = "CouldntGetConcurrencyLimit"
+
 	// TaskLoopRunReasonFailedValidation indicates that the TaskLoop failed runtime validation
 	TaskLoopRunReasonFailedValidation TaskLoopRunReason = "TaskLoopValidationFailed"
 
@@ -111,6 +283,10 @@ func (t TaskLoopRunReason) String() string {
 type TaskLoopRunStatus struct {
 	// TaskLoopSpec contains the exact spec used to instantiate the Run
 	TaskLoopSpec *TaskLoopSpec `json:"taskLoopSpec,omitempty"`
+	// TaskSpec contains the exact spec of the task used by the TaskRuns.
+	// It is resolved once per Run from the taskRef or taskSpec of the TaskLoop.
+	// +optional
+	TaskSpec *v1beta1.TaskSpec `json:"taskSpec,omitempty"`
 	// map of TaskLoopTaskRunStatus with the taskRun name as the key
 	// +optional
 	TaskRuns map[string]*TaskLoopTaskRunStatus `json:"taskRuns,omitempty"`
@@ -120,7 +296,127 @@ type TaskLoopRunStatus struct {
 type TaskLoopTaskRunStatus struct {
 	// iteration number
 	Iteration int `json:"iteration,omitempty"`
+	// IterationParams are the values of the iterate parameters used by the TaskRun
+	// +optional
+	IterationParams []v1beta1.Param `json:"iterationParams,omitempty"`
+	// Attempts is the number of times the TaskRun has run, including retries
+	// +optional
+	Attempts int `json:"attempts,omitempty"`
+	// NextRetryTime is the time at which the failed TaskRun is retried when it waits for a retry backoff
+	// +optional
+	NextRetryTime *metav1.Time `json:"nextRetryTime,omitempty"`
 	// Status is the TaskRunStatus for the corresponding TaskRun
 	// +optional
 	Status *v1beta1.TaskRunStatus `json:"status,omitempty"`
 }
+
+// Delay returns the delay before a retry of a TaskRun.  The first retry is number 1.
+func (b *RetryBackoff) Delay(retry int) time.Duration {
+	multiplier := b.Multiplier
+	if multiplier == 0 {
+		multiplier = 2
+	}
+	delay := b.InitialDelay.Duration
+	for i := 1; i < retry && delay < math.MaxInt64/time.Duration(multiplier); i++ {
+		delay *= time.Duration(multiplier)
+	}
+	if b.MaxDelay != nil && delay > b.MaxDelay.Duration {
+		delay = b.MaxDelay.Duration
+	}
+	return delay
+}
+
+// IterateValues returns the values to iterate over for an iterate parameter value.
+// A string value is split into an array, one item per line.
+func IterateValues(value v1beta1.ArrayOrString) []string {
+	if value.Type == v1beta1.ParamTypeString {
+		return strings.Split(strings.TrimSuffix(value.StringVal, "\n"), "\n")
+	}
+	return value.ArrayVal
+}
+
+// IterateObjects returns the JSON objects to iterate over for an iterate parameter value.
+// A string value must hold a JSON array of objects.  Each item of an array value must hold a JSON object.
+func IterateObjects(value v1beta1.ArrayOrString) ([]map[string]json.RawMessage, error) {
+	if value.Type == v1beta1.ParamTypeString {
+		var objects []map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(value.StringVal), &objects); err != nil {
+			return nil, fmt.Errorf("value is not a JSON array of objects: %w", err)
+		}
+		return objects, nil
+	}
+	objects := make([]map[string]json.RawMessage, len(value.ArrayVal))
+	for i, item := range value.ArrayVal {
+		if err := json.Unmarshal([]byte(item), &objects[i]); err != nil {
+			return nil, fmt.Errorf("item %d is not a JSON object: %w", i+1, err)
+		}
+	}
+	return objects, nil
+}
+
+// Values returns the values of a numeric range, resolving any references to Run parameters.
+func (n *IterateNumeric) Values(params []v1beta1.Param) ([]string, error) {
+	from, err := resolveNumericValue(n.From, 0, params)
+	if err != nil {
+		return nil, fmt.Errorf("invalid iterateNumeric from value: %w", err)
+	}
+	to, err := resolveNumericValue(n.To, 0, params)
+	if err != nil {
+		return nil, fmt.Errorf("invalid iterateNumeric to value: %w", err)
+	}
+	step, err := resolveNumericValue(n.Step, 1, params)
+	if err != nil {
+		return nil, fmt.Errorf("invalid iterateNumeric step value: %w", err)
+	}
+	if step == 0 {
+		return nil, fmt.Errorf("invalid iterateNumeric step value: step must not be 0")
+	}
+	if steps, ok := numericRangeSteps(from, to, step); ok && steps >= MaxNumericIterations {
+		return nil, fmt.Errorf("iterateNumeric range has more than the maximum of %d values", MaxNumericIterations)
+	}
+	var values []string
+	for i := from; (step > 0 && i <= to) || (step < 0 && i >= to); i += step {
+		values = append(values, strconv.Itoa(i))
+		// Stop before the next value overflows; it would be past the end of the range anyway.
+		if (step > 0 && i > math.MaxInt64-step) || (step < 0 && i < math.MinInt64-step) {
+			break
+		}
+	}
+	return values, nil
+}
+
+// numericRangeSteps returns the number of steps between the first and the last value of a numeric range,
+// or false if the range is empty.  The difference between the bounds is computed on unsigned integers
+// so that it doesn't overflow for ranges that span most of the int range.
+func numericRangeSteps(from, to, step int) (uint64, bool) {
+	if step > 0 && from <= to {
+		return (uint64(to) - uint64(from)) / uint64(step), true
+	}
+	if step < 0 && from >= to {
+		return (uint64(from) - uint64(to)) / (uint64(-(step + 1)) + 1), true
+	}
+	return 0, false
+}
+
+// IsParamReference returns the name of the parameter referenced by a value like "$(params.name)".
+func IsParamReference(value string) (string, bool) {
+	if strings.HasPrefix(value, "$(params.") && strings.HasSuffix(value, ")") {
+		return strings.TrimSuffix(strings.TrimPrefix(value, "$(params."), ")"), true
+	}
+	return "", false
+}
+
+func resolveNumericValue(value string, defaultValue int, params []v1beta1.Param) (int, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+	if name, ok := IsParamReference(value); ok {
+		for _, p := range params {
+			if p.Name == name {
+				return strconv.Atoi(strings.TrimSpace(p.Value.StringVal))
+			}
+		}
+		return 0, fmt.Errorf("parameter %q was not found", name)
+	}
+	return strconv.Atoi(value)
+}
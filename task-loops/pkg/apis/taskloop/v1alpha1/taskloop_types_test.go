@@ -0,0 +1,166 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	taskloopv1alpha1 "github.com/tektoncd/experimental/task-loops/pkg/apis/taskloop/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/test/diff"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRetryBackoff_Delay(t *testing.T) {
+	tests := []struct {
+		name           string
+		backoff        taskloopv1alpha1.RetryBackoff
+		expectedDelays []time.Duration
+	}{{
+		name:           "default multiplier",
+		backoff:        taskloopv1alpha1.RetryBackoff{InitialDelay: &metav1.Duration{Duration: time.Second}},
+		expectedDelays: []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second},
+	}, {
+		name: "multiplier",
+		backoff: taskloopv1alpha1.RetryBackoff{
+			InitialDelay: &metav1.Duration{Duration: time.Second},
+			Multiplier:   3,
+		},
+		expectedDelays: []time.Duration{time.Second, 3 * time.Second, 9 * time.Second},
+	}, {
+		name: "constant delay",
+		backoff: taskloopv1alpha1.RetryBackoff{
+			InitialDelay: &metav1.Duration{Duration: time.Second},
+			Multiplier:   1,
+		},
+		expectedDelays: []time.Duration{time.Second, time.Second, time.Second},
+	}, {
+		name: "max delay",
+		backoff: taskloopv1alpha1.RetryBackoff{
+			InitialDelay: &metav1.Duration{Duration: time.Second},
+			MaxDelay:     &metav1.Duration{Duration: 5 * time.Second},
+		},
+		expectedDelays: []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second},
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var delays []time.Duration
+			for retry := 1; retry <= len(tc.expectedDelays); retry++ {
+				delays = append(delays, tc.backoff.Delay(retry))
+			}
+			if d := cmp.Diff(tc.expectedDelays, delays); d != "" {
+				t.Errorf("Delays are different from expected. diff %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestIterateNumeric_Values(t *testing.T) {
+	params := []v1beta1.Param{{
+		Name:  "shards",
+		Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "4"},
+	}, {
+		Name:  "notanumber",
+		Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "four"},
+	}}
+	tests := []struct {
+		name           string
+		numeric        taskloopv1alpha1.IterateNumeric
+		expectedValues []string
+		expectedError  string
+	}{{
+		name:           "defaults",
+		numeric:        taskloopv1alpha1.IterateNumeric{Param: "i", To: "3"},
+		expectedValues: []string{"0", "1", "2", "3"},
+	}, {
+		name:           "parameter reference",
+		numeric:        taskloopv1alpha1.IterateNumeric{Param: "i", From: "1", To: "$(params.shards)"},
+		expectedValues: []string{"1", "2", "3", "4"},
+	}, {
+		name:           "step that does not reach the end",
+		numeric:        taskloopv1alpha1.IterateNumeric{Param: "i", From: "0", To: "10", Step: "4"},
+		expectedValues: []string{"0", "4", "8"},
+	}, {
+		name:           "negative step",
+		numeric:        taskloopv1alpha1.IterateNumeric{Param: "i", From: "3", To: "1", Step: "-1"},
+		expectedValues: []string{"3", "2", "1"},
+	}, {
+		name:           "empty range",
+		numeric:        taskloopv1alpha1.IterateNumeric{Param: "i", From: "3", To: "1"},
+		expectedValues: nil,
+	}, {
+		name:          "missing parameter",
+		numeric:       taskloopv1alpha1.IterateNumeric{Param: "i", To: "$(params.missing)"},
+		expectedError: `invalid iterateNumeric to value: parameter "missing" was not found`,
+	}, {
+		name:          "parameter that is not a number",
+		numeric:       taskloopv1alpha1.IterateNumeric{Param: "i", To: "$(params.notanumber)"},
+		expectedError: `invalid iterateNumeric to value: strconv.Atoi: parsing "four": invalid syntax`,
+	}, {
+		name:          "zero step",
+		numeric:       taskloopv1alpha1.IterateNumeric{Param: "i", To: "3", Step: "0"},
+		expectedError: "invalid iterateNumeric step value: step must not be 0",
+	}, {
+		name:           "range with the maximum number of values",
+		numeric:        taskloopv1alpha1.IterateNumeric{Param: "i", From: "1", To: "10000"},
+		expectedValues: numericValues(1, 10000),
+	}, {
+		name:          "range with too many values",
+		numeric:       taskloopv1alpha1.IterateNumeric{Param: "i", From: "0", To: "10000"},
+		expectedError: "iterateNumeric range has more than the maximum of 10000 values",
+	}, {
+		name:          "range that spans all integers",
+		numeric:       taskloopv1alpha1.IterateNumeric{Param: "i", From: "-9223372036854775808", To: "9223372036854775807"},
+		expectedError: "iterateNumeric range has more than the maximum of 10000 values",
+	}, {
+		name:           "range that ends at the largest integer",
+		numeric:        taskloopv1alpha1.IterateNumeric{Param: "i", From: "9223372036854775806", To: "9223372036854775807", Step: "2"},
+		expectedValues: []string{"9223372036854775806"},
+	}, {
+		name:           "negative range that ends at the smallest integer",
+		numeric:        taskloopv1alpha1.IterateNumeric{Param: "i", From: "-9223372036854775807", To: "-9223372036854775808", Step: "-9223372036854775808"},
+		expectedValues: []string{"-9223372036854775807"},
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			values, err := tc.numeric.Values(params)
+			if tc.expectedError != "" {
+				if err == nil || err.Error() != tc.expectedError {
+					t.Errorf("Expected error %q but got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if d := cmp.Diff(tc.expectedValues, values); d != "" {
+				t.Errorf("Values are different from expected. diff %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func numericValues(from, to int) []string {
+	var values []string
+	for i := from; i <= to; i++ {
+		values = append(values, strconv.Itoa(i))
+	}
+	return values
+}
@@ -18,9 +18,14 @@ package v1alpha1
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 	"strings"
 
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"github.com/tektoncd/pipeline/pkg/apis/validate"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"knative.dev/pkg/apis"
 )
@@ -41,9 +46,58 @@ func (tls *TaskLoopSpec) Validate(ctx context.Context) *apis.FieldError {
 	if err := validateTask(ctx, tls); err != nil {
 		return err
 	}
+	// Validate iterate parameters.
+	if err := validateIterateParams(tls); err != nil {
+		return err
+	}
+	// Validate the iterate fields against the parameters of an inline task spec.
+	// The parameters of a referenced task are checked when the TaskLoop is run.
+	if tls.TaskSpec != nil {
+		if err := tls.ValidateIterateFields(tls.TaskSpec.Params); err != nil {
+			return err
+		}
+	}
+	// Validate loop timeout.
+	if tls.LoopTimeout != nil && tls.LoopTimeout.Duration < 0 {
+		return apis.ErrInvalidValue(tls.LoopTimeout.Duration.String(), "spec.loopTimeout")
+	}
+	// Validate retry backoff.
+	if tls.RetryBackoff != nil {
+		if err := validateRetryBackoff(tls.RetryBackoff); err != nil {
+			return err.ViaField("spec.retryBackoff")
+		}
+	}
+	// Validate namespace concurrency.
+	if tls.NamespaceConcurrency != nil {
+		if err := validateNamespaceConcurrency(tls.NamespaceConcurrency); err != nil {
+			return err.ViaField("spec.namespaceConcurrency")
+		}
+	}
+	// Validate failure policy.
+	if err := validateFailurePolicy(tls); err != nil {
+		return err
+	}
+	// Validate results format.
+	if err := validateResults(tls); err != nil {
+		return err
+	}
+	// Validate iterate parameter values if the TaskLoop is being validated for a Run.
+	if params, ok := ctx.Value(runParamsKey{}).([]v1beta1.Param); ok {
+		if err := validateIterateParamValues(tls, params); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+type runParamsKey struct{}
+
+// WithRunParams returns a copy of the context carrying the parameters of a Run that executes the TaskLoop.
+// Validating a TaskLoopSpec with this context also checks the values of the iterate parameters.
+func WithRunParams(ctx context.Context, params []v1beta1.Param) context.Context {
+	return context.WithValue(ctx, runParamsKey{}, params)
+}
+
 func validateTask(ctx context.Context, tls *TaskLoopSpec) *apis.FieldError {
 	// taskRef and taskSpec are mutually exclusive.
 	if (tls.TaskRef != nil && tls.TaskRef.Name != "") && tls.TaskSpec != nil {
@@ -64,6 +118,274 @@ func validateTask(ctx context.Context, tls *TaskLoopSpec) *apis.FieldError {
 		if errSlice := validation.IsQualifiedName(tls.TaskRef.Name); len(errSlice) != 0 {
 			return apis.ErrInvalidValue(strings.Join(errSlice, ","), "spec.taskRef.name")
 		}
+		// taskRef kind must be a Task or a ClusterTask
+		switch tls.TaskRef.Kind {
+		case "", v1beta1.NamespacedTaskKind, v1beta1.ClusterTaskKind:
+		default:
+			return apis.ErrInvalidValue(tls.TaskRef.Kind, "spec.taskRef.kind")
+		}
+		// taskRef bundle must be a valid image reference
+		if tls.TaskRef.Bundle != "" {
+			if _, err := name.ParseReference(tls.TaskRef.Bundle); err != nil {
+				return apis.ErrInvalidValue(fmt.Sprintf("invalid bundle reference (%s)", err), "spec.taskRef.bundle")
+			}
+		}
+	}
+	return nil
+}
+
+func validateIterateParams(tls *TaskLoopSpec) *apis.FieldError {
+	// iterateParam and iterateParams are mutually exclusive.
+	if tls.IterateParam != "" && len(tls.IterateParams) != 0 {
+		return apis.ErrMultipleOneOf("spec.iterateParam", "spec.iterateParams")
+	}
+	// Each iterate parameter must be named and must be listed only once.
+	seen := make(map[string]struct{}, len(tls.IterateParams)+1)
+	if tls.IterateParam != "" {
+		seen[tls.IterateParam] = struct{}{}
+	}
+	for i, name := range tls.IterateParams {
+		if name == "" {
+			return apis.ErrInvalidArrayValue(name, "spec.iterateParams", i)
+		}
+		if _, ok := seen[name]; ok {
+			return apis.ErrInvalidArrayValue(fmt.Sprintf("duplicate iterate parameter %s", name), "spec.iterateParams", i)
+		}
+		seen[name] = struct{}{}
+	}
+	if tls.IterateNumeric != nil {
+		if err := validateIterateNumeric(tls.IterateNumeric, seen); err != nil {
+			return err.ViaField("spec.iterateNumeric")
+		}
+	}
+	if len(tls.IterateFields) != 0 {
+		if err := validateIterateFields(tls, seen); err != nil {
+			return err
+		}
+	}
+	switch tls.IterationMode {
+	case "", IterationModeMatrix, IterationModeZip:
+	default:
+		return apis.ErrInvalidValue(tls.IterationMode, "spec.iterationMode")
+	}
+	return nil
+}
+
+func validateIterateNumeric(n *IterateNumeric, iterateParams map[string]struct{}) *apis.FieldError {
+	if n.Param == "" {
+		return apis.ErrMissingField("param")
+	}
+	if _, ok := iterateParams[n.Param]; ok {
+		return apis.ErrInvalidValue(fmt.Sprintf("parameter %s is already an iterate parameter", n.Param), "param")
+	}
+	if n.To == "" {
+		return apis.ErrMissingField("to")
+	}
+	// Each value must be an integer or a parameter reference.  A literal step must not be 0.
+	fields := []struct{ name, value string }{{"from", n.From}, {"to", n.To}, {"step", n.Step}}
+	for _, f := range fields {
+		if _, ok := IsParamReference(f.value); ok || f.value == "" {
+			continue
+		}
+		if i, err := strconv.Atoi(f.value); err != nil || (f.name == "step" && i == 0) {
+			return apis.ErrInvalidValue(f.value, f.name)
+		}
+	}
+	return nil
+}
+
+func validateIterateFields(tls *TaskLoopSpec, iterateParams map[string]struct{}) *apis.FieldError {
+	// The fields are taken from the objects held by iterateParam.
+	if tls.IterateParam == "" {
+		return apis.ErrGeneric("iterateFields can only be used with iterateParam", "spec.iterateFields")
+	}
+	// Each mapping must name a field and a parameter, and each parameter can receive only one field.
+	params := make(map[string]struct{}, len(tls.IterateFields))
+	for i, f := range tls.IterateFields {
+		if f.Field == "" {
+			return apis.ErrMissingField(fmt.Sprintf("spec.iterateFields[%d].field", i))
+		}
+		if f.Param == "" {
+			return apis.ErrMissingField(fmt.Sprintf("spec.iterateFields[%d].param", i))
+		}
+		if _, ok := iterateParams[f.Param]; ok || (tls.IterateNumeric != nil && tls.IterateNumeric.Param == f.Param) {
+			return apis.ErrInvalidValue(fmt.Sprintf("parameter %s is already an iterate parameter", f.Param),
+				fmt.Sprintf("spec.iterateFields[%d].param", i))
+		}
+		if _, ok := params[f.Param]; ok {
+			return apis.ErrInvalidValue(fmt.Sprintf("duplicate parameter %s", f.Param),
+				fmt.Sprintf("spec.iterateFields[%d].param", i))
+		}
+		params[f.Param] = struct{}{}
+	}
+	return nil
+}
+
+// ValidateIterateFields checks that the task parameters which iterateFields map onto are declared by the task.
+func (tls *TaskLoopSpec) ValidateIterateFields(paramSpecs []v1beta1.ParamSpec) *apis.FieldError {
+	for i, f := range tls.IterateFields {
+		found := false
+		for _, ps := range paramSpecs {
+			if ps.Name == f.Param {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return apis.ErrInvalidValue(fmt.Sprintf("parameter %s is not declared by the task", f.Param),
+				fmt.Sprintf("spec.iterateFields[%d].param", i))
+		}
+	}
+	return nil
+}
+
+func validateIterateParamValues(tls *TaskLoopSpec, params []v1beta1.Param) *apis.FieldError {
+	// The numeric range must be computable from the parameter values.
+	if tls.IterateNumeric != nil {
+		if _, err := tls.IterateNumeric.Values(params); err != nil {
+			return apis.ErrGeneric(err.Error(), "spec.iterateNumeric")
+		}
+	}
+	// The value of an iterate parameter with iterate fields must hold JSON objects.
+	// Missing parameters are reported when the iterations are computed.
+	var objectCount int
+	if len(tls.IterateFields) != 0 {
+		for _, p := range params {
+			if p.Name != tls.IterateParam {
+				continue
+			}
+			objects, err := IterateObjects(p.Value)
+			if err != nil {
+				return apis.ErrGeneric(fmt.Sprintf("iterate parameter %q: %s", p.Name, err), "spec.iterateFields")
+			}
+			objectCount = len(objects)
+		}
+	}
+	// In zip mode all of the iterate parameters must have the same number of values.
+	if tls.IterationMode != IterationModeZip {
+		return nil
+	}
+	firstName, firstLen := "", -1
+	if tls.IterateNumeric != nil {
+		values, _ := tls.IterateNumeric.Values(params)
+		firstName, firstLen = tls.IterateNumeric.Param, len(values)
+	}
+	names, path := tls.IterateParams, "spec.iterateParams"
+	if tls.IterateParam != "" {
+		names, path = []string{tls.IterateParam}, "spec.iterateParam"
+	}
+	for _, name := range names {
+		for _, p := range params {
+			if p.Name != name {
+				continue
+			}
+			n := len(IterateValues(p.Value))
+			if len(tls.IterateFields) != 0 {
+				n = objectCount
+			}
+			if firstLen == -1 {
+				firstName, firstLen = name, n
+			} else if n != firstLen {
+				return apis.ErrGeneric(fmt.Sprintf("iterate parameter %q has %d values but %q has %d", name, n, firstName, firstLen),
+					path)
+			}
+		}
+	}
+	return nil
+}
+
+func validateRetryBackoff(b *RetryBackoff) *apis.FieldError {
+	if b.InitialDelay == nil {
+		return apis.ErrMissingField("initialDelay")
+	}
+	if b.InitialDelay.Duration <= 0 {
+		return apis.ErrInvalidValue(b.InitialDelay.Duration.String(), "initialDelay")
+	}
+	if b.Multiplier < 0 {
+		return apis.ErrInvalidValue(b.Multiplier, "multiplier")
+	}
+	if b.MaxDelay != nil && b.MaxDelay.Duration < b.InitialDelay.Duration {
+		return apis.ErrInvalidValue(fmt.Sprintf("%s is less than initialDelay", b.MaxDelay.Duration), "maxDelay")
+	}
+	return nil
+}
+
+func validateNamespaceConcurrency(nc *NamespaceConcurrency) *apis.FieldError {
+	if nc.LabelKey != "" {
+		if errSlice := validation.IsQualifiedName(nc.LabelKey); len(errSlice) != 0 {
+			return apis.ErrInvalidValue(strings.Join(errSlice, ","), "labelKey")
+		}
+	}
+	// limit and configMapKeyRef are mutually exclusive.
+	if nc.Limit != nil && nc.ConfigMapKeyRef != nil {
+		return apis.ErrMultipleOneOf("limit", "configMapKeyRef")
+	}
+	if nc.Limit == nil && nc.ConfigMapKeyRef == nil {
+		return apis.ErrMissingOneOf("limit", "configMapKeyRef")
+	}
+	if nc.Limit != nil && *nc.Limit < 1 {
+		return apis.ErrInvalidValue(*nc.Limit, "limit")
+	}
+	if nc.ConfigMapKeyRef != nil {
+		if nc.ConfigMapKeyRef.Name == "" {
+			return apis.ErrMissingField("configMapKeyRef.name")
+		}
+		if nc.ConfigMapKeyRef.Key == "" {
+			return apis.ErrMissingField("configMapKeyRef.key")
+		}
+	}
+	return nil
+}
+
+func validateFailurePolicy(tls *TaskLoopSpec) *apis.FieldError {
+	switch tls.FailurePolicy {
+	case "", FailurePolicyFailFast, FailurePolicyRunAll:
+		if tls.FailureThreshold != nil {
+			return apis.ErrDisallowedFields("spec.failureThreshold")
+		}
+	case FailurePolicyThreshold:
+		if tls.FailureThreshold == nil {
+			return apis.ErrMissingField("spec.failureThreshold")
+		}
+		// The threshold must be a positive number or a percentage between 1% and 100%.
+		if tls.FailureThreshold.Type == intstr.Int {
+			if tls.FailureThreshold.IntVal < 1 {
+				return apis.ErrInvalidValue(tls.FailureThreshold.String(), "spec.failureThreshold")
+			}
+		} else {
+			percent, err := strconv.Atoi(strings.TrimSuffix(tls.FailureThreshold.StrVal, "%"))
+			if err != nil || !strings.HasSuffix(tls.FailureThreshold.StrVal, "%") || percent < 1 || percent > 100 {
+				return apis.ErrInvalidValue(tls.FailureThreshold.String(), "spec.failureThreshold")
+			}
+		}
+	default:
+		return apis.ErrInvalidValue(tls.FailurePolicy, "spec.failurePolicy")
+	}
+	return nil
+}
+
+func validateResults(tls *TaskLoopSpec) *apis.FieldError {
+	switch tls.ResultsFormat {
+	case "", ResultsFormatArray, ResultsFormatPerIteration:
+	default:
+		return apis.ErrInvalidValue(tls.ResultsFormat, "spec.resultsFormat")
+	}
+	if tls.TaskSpec != nil {
+		if err := ValidateTaskResults(tls.TaskSpec.Results); err != nil {
+			return err.ViaField("taskSpec").ViaField("spec")
+		}
+	}
+	return nil
+}
+
+// ValidateTaskResults checks that the task doesn't declare a result whose name is reserved by TaskLoop.
+// The failed iterations are published as a Run result so a Task can't produce a result with the same name.
+func ValidateTaskResults(results []v1beta1.TaskResult) *apis.FieldError {
+	for i, r := range results {
+		if r.Name == FailedIterationsResultName {
+			return apis.ErrGeneric(fmt.Sprintf("result name %q is reserved by TaskLoop", r.Name),
+				fmt.Sprintf("results[%d].name", i))
+		}
 	}
 	return nil
 }
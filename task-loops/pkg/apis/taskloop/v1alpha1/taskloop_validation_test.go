@@ -19,6 +19,7 @@ package v1alpha1_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -27,10 +28,16 @@ import (
 	"github.com/tektoncd/pipeline/test/diff"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"knative.dev/pkg/apis"
 )
 
+func intOrStringPtr(v intstr.IntOrString) *intstr.IntOrString {
+	return &v
+}
+
 func TestTaskLoop_Validate_Success(t *testing.T) {
+	limit := 20
 	tests := []struct {
 		name string
 		tl   *taskloopv1alpha1.TaskLoop
@@ -42,6 +49,22 @@ func TestTaskLoop_Validate_Success(t *testing.T) {
 				TaskRef: &v1beta1.TaskRef{Name: "mytask"},
 			},
 		},
+	}, {
+		name: "taskRef to a cluster task",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef: &v1beta1.TaskRef{Name: "mytask", Kind: v1beta1.ClusterTaskKind},
+			},
+		},
+	}, {
+		name: "taskRef to a bundle",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef: &v1beta1.TaskRef{Name: "mytask", Bundle: "gcr.io/my-project/my-bundle:v1"},
+			},
+		},
 	}, {
 		name: "taskSpec",
 		tl: &taskloopv1alpha1.TaskLoop{
@@ -54,6 +77,139 @@ func TestTaskLoop_Validate_Success(t *testing.T) {
 				},
 			},
 		},
+	}, {
+		name: "iterateParams",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef:       &v1beta1.TaskRef{Name: "mytask"},
+				IterateParams: []string{"os", "arch"},
+			},
+		},
+	}, {
+		name: "iterateParams in zip mode",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef:       &v1beta1.TaskRef{Name: "mytask"},
+				IterateParams: []string{"images", "tags"},
+				IterationMode: taskloopv1alpha1.IterationModeZip,
+			},
+		},
+	}, {
+		name: "iterateFields",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskSpec: &v1beta1.TaskSpec{
+					Params: []v1beta1.ParamSpec{{Name: "service", Type: v1beta1.ParamTypeString}, {Name: "cluster", Type: v1beta1.ParamTypeString}},
+					Steps: []v1beta1.Step{{
+						Container: corev1.Container{Name: "foo", Image: "bar"},
+					}},
+				},
+				IterateParam: "deployments",
+				IterateFields: []taskloopv1alpha1.IterateField{
+					{Field: "service", Param: "service"},
+					{Field: "region", Param: "cluster"},
+				},
+			},
+		},
+	}, {
+		name: "iterateNumeric",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+				IterateNumeric: &taskloopv1alpha1.IterateNumeric{
+					Param: "shard",
+					To:    "$(params.shards)",
+				},
+			},
+		},
+	}, {
+		name: "iterateNumeric with iterateParam",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef:      &v1beta1.TaskRef{Name: "mytask"},
+				IterateParam: "os",
+				IterateNumeric: &taskloopv1alpha1.IterateNumeric{
+					Param: "shard",
+					From:  "10",
+					To:    "0",
+					Step:  "-2",
+				},
+			},
+		},
+	}, {
+		name: "retryBackoff",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+				Retries: 3,
+				RetryBackoff: &taskloopv1alpha1.RetryBackoff{
+					InitialDelay: &metav1.Duration{Duration: 10 * time.Second},
+					Multiplier:   3,
+					MaxDelay:     &metav1.Duration{Duration: time.Minute},
+				},
+			},
+		},
+	}, {
+		name: "namespaceConcurrency with a limit",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+				NamespaceConcurrency: &taskloopv1alpha1.NamespaceConcurrency{
+					LabelKey: "example.com/team",
+					Limit:    &limit,
+				},
+			},
+		},
+	}, {
+		name: "namespaceConcurrency with a ConfigMap",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+				NamespaceConcurrency: &taskloopv1alpha1.NamespaceConcurrency{
+					ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "limits"},
+						Key:                  "taskloops",
+					},
+				},
+			},
+		},
+	}, {
+		name: "runAll failure policy",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef:       &v1beta1.TaskRef{Name: "mytask"},
+				FailurePolicy: taskloopv1alpha1.FailurePolicyRunAll,
+			},
+		},
+	}, {
+		name: "threshold failure policy with a number",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef:          &v1beta1.TaskRef{Name: "mytask"},
+				FailurePolicy:    taskloopv1alpha1.FailurePolicyThreshold,
+				FailureThreshold: intOrStringPtr(intstr.FromInt(3)),
+			},
+		},
+	}, {
+		name: "threshold failure policy with a percentage",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef:          &v1beta1.TaskRef{Name: "mytask"},
+				FailurePolicy:    taskloopv1alpha1.FailurePolicyThreshold,
+				FailureThreshold: intOrStringPtr(intstr.FromString("25%")),
+			},
+		},
 	}}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
@@ -66,6 +222,7 @@ func TestTaskLoop_Validate_Success(t *testing.T) {
 }
 
 func TestTaskLoop_Validate_Error(t *testing.T) {
+	limit, zero := 20, 0
 	tests := []struct {
 		name          string
 		tl            *taskloopv1alpha1.TaskLoop
@@ -97,6 +254,30 @@ func TestTaskLoop_Validate_Error(t *testing.T) {
 			Message: "expected exactly one, got both",
 			Paths:   []string{"spec.taskRef", "spec.taskSpec"},
 		},
+	}, {
+		name: "invalid taskRef kind",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef: &v1beta1.TaskRef{Name: "mytask", Kind: "Pipeline"},
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: Pipeline",
+			Paths:   []string{"spec.taskRef.kind"},
+		},
+	}, {
+		name: "invalid taskRef bundle",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef: &v1beta1.TaskRef{Name: "mytask", Bundle: "invalid reference"},
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: invalid bundle reference (could not parse reference: invalid reference)",
+			Paths:   []string{"spec.taskRef.bundle"},
+		},
 	}, {
 		name: "invalid taskRef",
 		tl: &taskloopv1alpha1.TaskLoop{
@@ -128,6 +309,456 @@ func TestTaskLoop_Validate_Error(t *testing.T) {
 			Details: "Task step name must be a valid DNS Label, For more info refer to https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names",
 			Paths:   []string{"spec.taskSpec.steps[0].name"},
 		},
+	}, {
+		name: "both iterateParam and iterateParams",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef:       &v1beta1.TaskRef{Name: "mytask"},
+				IterateParam:  "os",
+				IterateParams: []string{"os", "arch"},
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "expected exactly one, got both",
+			Paths:   []string{"spec.iterateParam", "spec.iterateParams"},
+		},
+	}, {
+		name: "empty name in iterateParams",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef:       &v1beta1.TaskRef{Name: "mytask"},
+				IterateParams: []string{"os", ""},
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: ",
+			Paths:   []string{"spec.iterateParams[1]"},
+		},
+	}, {
+		name: "duplicate name in iterateParams",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef:       &v1beta1.TaskRef{Name: "mytask"},
+				IterateParams: []string{"os", "arch", "os"},
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: duplicate iterate parameter os",
+			Paths:   []string{"spec.iterateParams[2]"},
+		},
+	}, {
+		name: "invalid iterationMode",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef:       &v1beta1.TaskRef{Name: "mytask"},
+				IterateParams: []string{"os", "arch"},
+				IterationMode: "spiral",
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: spiral",
+			Paths:   []string{"spec.iterationMode"},
+		},
+	}, {
+		name: "iterateFields without iterateParam",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef:       &v1beta1.TaskRef{Name: "mytask"},
+				IterateParams: []string{"deployments", "regions"},
+				IterateFields: []taskloopv1alpha1.IterateField{{Field: "service", Param: "service"}},
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "iterateFields can only be used with iterateParam",
+			Paths:   []string{"spec.iterateFields"},
+		},
+	}, {
+		name: "iterateFields without field",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef:       &v1beta1.TaskRef{Name: "mytask"},
+				IterateParam:  "deployments",
+				IterateFields: []taskloopv1alpha1.IterateField{{Param: "service"}},
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "missing field(s)",
+			Paths:   []string{"spec.iterateFields[0].field"},
+		},
+	}, {
+		name: "iterateFields with the iterate parameter",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef:       &v1beta1.TaskRef{Name: "mytask"},
+				IterateParam:  "deployments",
+				IterateFields: []taskloopv1alpha1.IterateField{{Field: "service", Param: "deployments"}},
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: parameter deployments is already an iterate parameter",
+			Paths:   []string{"spec.iterateFields[0].param"},
+		},
+	}, {
+		name: "iterateFields with a duplicate parameter",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef:      &v1beta1.TaskRef{Name: "mytask"},
+				IterateParam: "deployments",
+				IterateFields: []taskloopv1alpha1.IterateField{
+					{Field: "service", Param: "service"},
+					{Field: "name", Param: "service"},
+				},
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: duplicate parameter service",
+			Paths:   []string{"spec.iterateFields[1].param"},
+		},
+	}, {
+		name: "iterateFields with a parameter that the taskSpec does not declare",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskSpec: &v1beta1.TaskSpec{
+					Params: []v1beta1.ParamSpec{{Name: "service", Type: v1beta1.ParamTypeString}},
+					Steps: []v1beta1.Step{{
+						Container: corev1.Container{Name: "foo", Image: "bar"},
+					}},
+				},
+				IterateParam: "deployments",
+				IterateFields: []taskloopv1alpha1.IterateField{
+					{Field: "service", Param: "service"},
+					{Field: "region", Param: "cluster"},
+				},
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: parameter cluster is not declared by the task",
+			Paths:   []string{"spec.iterateFields[1].param"},
+		},
+	}, {
+		name: "iterateNumeric without param",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef:        &v1beta1.TaskRef{Name: "mytask"},
+				IterateNumeric: &taskloopv1alpha1.IterateNumeric{To: "3"},
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "missing field(s)",
+			Paths:   []string{"spec.iterateNumeric.param"},
+		},
+	}, {
+		name: "iterateNumeric without to",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef:        &v1beta1.TaskRef{Name: "mytask"},
+				IterateNumeric: &taskloopv1alpha1.IterateNumeric{Param: "shard"},
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "missing field(s)",
+			Paths:   []string{"spec.iterateNumeric.to"},
+		},
+	}, {
+		name: "iterateNumeric with the same param as iterateParam",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef:        &v1beta1.TaskRef{Name: "mytask"},
+				IterateParam:   "shard",
+				IterateNumeric: &taskloopv1alpha1.IterateNumeric{Param: "shard", To: "3"},
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: parameter shard is already an iterate parameter",
+			Paths:   []string{"spec.iterateNumeric.param"},
+		},
+	}, {
+		name: "iterateNumeric with a value that is not a number",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef:        &v1beta1.TaskRef{Name: "mytask"},
+				IterateNumeric: &taskloopv1alpha1.IterateNumeric{Param: "shard", From: "one", To: "3"},
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: one",
+			Paths:   []string{"spec.iterateNumeric.from"},
+		},
+	}, {
+		name: "iterateNumeric with a zero step",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef:        &v1beta1.TaskRef{Name: "mytask"},
+				IterateNumeric: &taskloopv1alpha1.IterateNumeric{Param: "shard", To: "3", Step: "0"},
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: 0",
+			Paths:   []string{"spec.iterateNumeric.step"},
+		},
+	}, {
+		name: "negative loopTimeout",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef:     &v1beta1.TaskRef{Name: "mytask"},
+				LoopTimeout: &metav1.Duration{Duration: -time.Minute},
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: -1m0s",
+			Paths:   []string{"spec.loopTimeout"},
+		},
+	}, {
+		name: "retryBackoff without initialDelay",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef:      &v1beta1.TaskRef{Name: "mytask"},
+				RetryBackoff: &taskloopv1alpha1.RetryBackoff{},
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "missing field(s)",
+			Paths:   []string{"spec.retryBackoff.initialDelay"},
+		},
+	}, {
+		name: "retryBackoff with a negative multiplier",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+				RetryBackoff: &taskloopv1alpha1.RetryBackoff{
+					InitialDelay: &metav1.Duration{Duration: time.Second},
+					Multiplier:   -1,
+				},
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: -1",
+			Paths:   []string{"spec.retryBackoff.multiplier"},
+		},
+	}, {
+		name: "retryBackoff with maxDelay less than initialDelay",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+				RetryBackoff: &taskloopv1alpha1.RetryBackoff{
+					InitialDelay: &metav1.Duration{Duration: time.Minute},
+					MaxDelay:     &metav1.Duration{Duration: time.Second},
+				},
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: 1s is less than initialDelay",
+			Paths:   []string{"spec.retryBackoff.maxDelay"},
+		},
+	}, {
+		name: "namespaceConcurrency without a limit",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef:              &v1beta1.TaskRef{Name: "mytask"},
+				NamespaceConcurrency: &taskloopv1alpha1.NamespaceConcurrency{},
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "expected exactly one, got neither",
+			Paths:   []string{"spec.namespaceConcurrency.configMapKeyRef", "spec.namespaceConcurrency.limit"},
+		},
+	}, {
+		name: "namespaceConcurrency with both a limit and a ConfigMap",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+				NamespaceConcurrency: &taskloopv1alpha1.NamespaceConcurrency{
+					Limit: &limit,
+					ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "limits"},
+						Key:                  "taskloops",
+					},
+				},
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "expected exactly one, got both",
+			Paths:   []string{"spec.namespaceConcurrency.configMapKeyRef", "spec.namespaceConcurrency.limit"},
+		},
+	}, {
+		name: "namespaceConcurrency with a limit less than 1",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+				NamespaceConcurrency: &taskloopv1alpha1.NamespaceConcurrency{
+					Limit: &zero,
+				},
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: 0",
+			Paths:   []string{"spec.namespaceConcurrency.limit"},
+		},
+	}, {
+		name: "namespaceConcurrency with a ConfigMap without a key",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+				NamespaceConcurrency: &taskloopv1alpha1.NamespaceConcurrency{
+					ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "limits"},
+					},
+				},
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "missing field(s)",
+			Paths:   []string{"spec.namespaceConcurrency.configMapKeyRef.key"},
+		},
+	}, {
+		name: "namespaceConcurrency with an invalid labelKey",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+				NamespaceConcurrency: &taskloopv1alpha1.NamespaceConcurrency{
+					LabelKey: "not a label",
+					Limit:    &limit,
+				},
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: name part must consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character (e.g. 'MyName',  or 'my.name',  or '123-abc', regex used for validation is '([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]')",
+			Paths:   []string{"spec.namespaceConcurrency.labelKey"},
+		},
+	}, {
+		name: "invalid failurePolicy",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef:       &v1beta1.TaskRef{Name: "mytask"},
+				FailurePolicy: "sometimes",
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: sometimes",
+			Paths:   []string{"spec.failurePolicy"},
+		},
+	}, {
+		name: "threshold failure policy without failureThreshold",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef:       &v1beta1.TaskRef{Name: "mytask"},
+				FailurePolicy: taskloopv1alpha1.FailurePolicyThreshold,
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "missing field(s)",
+			Paths:   []string{"spec.failureThreshold"},
+		},
+	}, {
+		name: "failureThreshold without threshold failure policy",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef:          &v1beta1.TaskRef{Name: "mytask"},
+				FailureThreshold: intOrStringPtr(intstr.FromInt(3)),
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "must not set the field(s)",
+			Paths:   []string{"spec.failureThreshold"},
+		},
+	}, {
+		name: "zero failureThreshold",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef:          &v1beta1.TaskRef{Name: "mytask"},
+				FailurePolicy:    taskloopv1alpha1.FailurePolicyThreshold,
+				FailureThreshold: intOrStringPtr(intstr.FromInt(0)),
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: 0",
+			Paths:   []string{"spec.failureThreshold"},
+		},
+	}, {
+		name: "failureThreshold percentage out of range",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef:          &v1beta1.TaskRef{Name: "mytask"},
+				FailurePolicy:    taskloopv1alpha1.FailurePolicyThreshold,
+				FailureThreshold: intOrStringPtr(intstr.FromString("150%")),
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: 150%",
+			Paths:   []string{"spec.failureThreshold"},
+		},
+	}, {
+		name: "failureThreshold that is not a percentage",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef:          &v1beta1.TaskRef{Name: "mytask"},
+				FailurePolicy:    taskloopv1alpha1.FailurePolicyThreshold,
+				FailureThreshold: intOrStringPtr(intstr.FromString("half")),
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: half",
+			Paths:   []string{"spec.failureThreshold"},
+		},
+	}, {
+		name: "invalid resultsFormat",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef:       &v1beta1.TaskRef{Name: "mytask"},
+				ResultsFormat: "csv",
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: csv",
+			Paths:   []string{"spec.resultsFormat"},
+		},
+	}, {
+		name: "taskSpec with reserved result name",
+		tl: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskloop"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskSpec: &v1beta1.TaskSpec{
+					Results: []v1beta1.TaskResult{{Name: "failed-iterations"}},
+					Steps: []v1beta1.Step{{
+						Container: corev1.Container{Name: "foo", Image: "bar"},
+					}},
+				},
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: `result name "failed-iterations" is reserved by TaskLoop`,
+			Paths:   []string{"spec.taskSpec.results[0].name"},
+		},
 	}}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
@@ -142,3 +773,93 @@ func TestTaskLoop_Validate_Error(t *testing.T) {
 		})
 	}
 }
+
+func TestTaskLoopSpec_Validate_RunParams(t *testing.T) {
+	zipSpec := taskloopv1alpha1.TaskLoopSpec{
+		TaskRef:       &v1beta1.TaskRef{Name: "mytask"},
+		IterateParams: []string{"images", "tags"},
+		IterationMode: taskloopv1alpha1.IterationModeZip,
+	}
+	objectsSpec := taskloopv1alpha1.TaskLoopSpec{
+		TaskRef:        &v1beta1.TaskRef{Name: "mytask"},
+		IterateParam:   "deployments",
+		IterateFields:  []taskloopv1alpha1.IterateField{{Field: "service", Param: "service"}},
+		IterateNumeric: &taskloopv1alpha1.IterateNumeric{Param: "shard", From: "1", To: "2"},
+		IterationMode:  taskloopv1alpha1.IterationModeZip,
+	}
+	tests := []struct {
+		name          string
+		spec          *taskloopv1alpha1.TaskLoopSpec
+		params        []v1beta1.Param
+		expectedError *apis.FieldError
+	}{{
+		name: "same lengths",
+		params: []v1beta1.Param{{
+			Name:  "images",
+			Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeArray, ArrayVal: []string{"api", "web"}},
+		}, {
+			Name:  "tags",
+			Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "v1\nv2\n"},
+		}},
+	}, {
+		name: "different lengths",
+		params: []v1beta1.Param{{
+			Name:  "images",
+			Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeArray, ArrayVal: []string{"api", "web"}},
+		}, {
+			Name:  "tags",
+			Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeArray, ArrayVal: []string{"v1"}},
+		}},
+		expectedError: &apis.FieldError{
+			Message: `iterate parameter "tags" has 1 values but "images" has 2`,
+			Paths:   []string{"spec.iterateParams"},
+		},
+	}, {
+		name: "JSON objects with the same length as the numeric range",
+		spec: &objectsSpec,
+		params: []v1beta1.Param{{
+			Name:  "deployments",
+			Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeArray, ArrayVal: []string{`{"service":"api"}`, `{"service":"web"}`}},
+		}},
+	}, {
+		name: "JSON objects with a different length than the numeric range",
+		spec: &objectsSpec,
+		params: []v1beta1.Param{{
+			Name:  "deployments",
+			Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: `[{"service":"api"}]`},
+		}},
+		expectedError: &apis.FieldError{
+			Message: `iterate parameter "deployments" has 1 values but "shard" has 2`,
+			Paths:   []string{"spec.iterateParam"},
+		},
+	}, {
+		name: "value that is not a JSON array of objects",
+		spec: &objectsSpec,
+		params: []v1beta1.Param{{
+			Name:  "deployments",
+			Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "api\nweb\n"},
+		}},
+		expectedError: &apis.FieldError{
+			Message: `iterate parameter "deployments": value is not a JSON array of objects: invalid character 'a' looking for beginning of value`,
+			Paths:   []string{"spec.iterateFields"},
+		},
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := tc.spec
+			if spec == nil {
+				spec = &zipSpec
+			}
+			err := spec.Validate(taskloopv1alpha1.WithRunParams(context.Background(), tc.params))
+			if tc.expectedError == nil {
+				if err != nil {
+					t.Errorf("Unexpected error for %s: %s", tc.name, err)
+				}
+			} else if err == nil {
+				t.Errorf("Expected an Error but did not get one for %s", tc.name)
+			} else if d := cmp.Diff(tc.expectedError.Error(), err.Error()); d != "" {
+				t.Errorf("Error is different from expected for %s. diff %s", tc.name, diff.PrintWantGot(d))
+			}
+		})
+	}
+}
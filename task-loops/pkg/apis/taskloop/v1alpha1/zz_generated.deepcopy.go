@@ -22,10 +22,96 @@ package v1alpha1
 
 import (
 	v1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	intstr "k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IterateField) DeepCopyInto(out *IterateField) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IterateField.
+func (in *IterateField) DeepCopy() *IterateField {
+	if in == nil {
+		return nil
+	}
+	out := new(IterateField)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IterateNumeric) DeepCopyInto(out *IterateNumeric) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IterateNumeric.
+func (in *IterateNumeric) DeepCopy() *IterateNumeric {
+	if in == nil {
+		return nil
+	}
+	out := new(IterateNumeric)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceConcurrency) DeepCopyInto(out *NamespaceConcurrency) {
+	*out = *in
+	if in.Limit != nil {
+		in, out := &in.Limit, &out.Limit
+		*out = new(int)
+		**out = **in
+	}
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceConcurrency.
+func (in *NamespaceConcurrency) DeepCopy() *NamespaceConcurrency {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceConcurrency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetryBackoff) DeepCopyInto(out *RetryBackoff) {
+	*out = *in
+	if in.InitialDelay != nil {
+		in, out := &in.InitialDelay, &out.InitialDelay
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxDelay != nil {
+		in, out := &in.MaxDelay, &out.MaxDelay
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetryBackoff.
+func (in *RetryBackoff) DeepCopy() *RetryBackoff {
+	if in == nil {
+		return nil
+	}
+	out := new(RetryBackoff)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TaskLoop) DeepCopyInto(out *TaskLoop) {
 	*out = *in
@@ -94,6 +180,11 @@ func (in *TaskLoopRunStatus) DeepCopyInto(out *TaskLoopRunStatus) {
 		*out = new(TaskLoopSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.TaskSpec != nil {
+		in, out := &in.TaskSpec, &out.TaskSpec
+		*out = new(v1beta1.TaskSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.TaskRuns != nil {
 		in, out := &in.TaskRuns, &out.TaskRuns
 		*out = make(map[string]*TaskLoopTaskRunStatus, len(*in))
@@ -135,16 +226,51 @@ func (in *TaskLoopSpec) DeepCopyInto(out *TaskLoopSpec) {
 		*out = new(v1beta1.TaskSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.IterateParams != nil {
+		in, out := &in.IterateParams, &out.IterateParams
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IterateFields != nil {
+		in, out := &in.IterateFields, &out.IterateFields
+		*out = make([]IterateField, len(*in))
+		copy(*out, *in)
+	}
+	if in.IterateNumeric != nil {
+		in, out := &in.IterateNumeric, &out.IterateNumeric
+		*out = new(IterateNumeric)
+		**out = **in
+	}
 	if in.Timeout != nil {
 		in, out := &in.Timeout, &out.Timeout
 		*out = new(v1.Duration)
 		**out = **in
 	}
+	if in.LoopTimeout != nil {
+		in, out := &in.LoopTimeout, &out.LoopTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.RetryBackoff != nil {
+		in, out := &in.RetryBackoff, &out.RetryBackoff
+		*out = new(RetryBackoff)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Concurrency != nil {
 		in, out := &in.Concurrency, &out.Concurrency
 		*out = new(int)
 		**out = **in
 	}
+	if in.NamespaceConcurrency != nil {
+		in, out := &in.NamespaceConcurrency, &out.NamespaceConcurrency
+		*out = new(NamespaceConcurrency)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
 	return
 }
 
@@ -161,6 +287,17 @@ func (in *TaskLoopSpec) DeepCopy() *TaskLoopSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TaskLoopTaskRunStatus) DeepCopyInto(out *TaskLoopTaskRunStatus) {
 	*out = *in
+	if in.IterationParams != nil {
+		in, out := &in.IterationParams, &out.IterationParams
+		*out = make([]v1beta1.Param, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NextRetryTime != nil {
+		in, out := &in.NextRetryTime, &out.NextRetryTime
+		*out = (*in).DeepCopy()
+	}
 	if in.Status != nil {
 		in, out := &in.Status, &out.Status
 		*out = new(v1beta1.TaskRunStatus)
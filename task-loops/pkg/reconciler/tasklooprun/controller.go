@@ -29,6 +29,7 @@ import (
 	runreconciler "github.com/tektoncd/pipeline/pkg/client/injection/reconciler/pipeline/v1alpha1/run"
 	pipelinecontroller "github.com/tektoncd/pipeline/pkg/controller"
 	"k8s.io/client-go/tools/cache"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/logging"
@@ -39,6 +40,7 @@ func NewController(namespace string) func(context.Context, configmap.Watcher) *c
 	return func(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
 
 		logger := logging.FromContext(ctx)
+		kubeclientset := kubeclient.Get(ctx)
 		pipelineclientset := pipelineclient.Get(ctx)
 		taskloopclientset := taskloopclient.Get(ctx)
 		runInformer := runinformer.Get(ctx)
@@ -46,6 +48,7 @@ func NewController(namespace string) func(context.Context, configmap.Watcher) *c
 		taskRunInformer := taskruninformer.Get(ctx)
 
 		c := &Reconciler{
+			kubeClientSet:     kubeclientset,
 			pipelineClientSet: pipelineclientset,
 			taskloopClientSet: taskloopclientset,
 			runLister:         runInformer.Lister(),
@@ -59,6 +62,8 @@ func NewController(namespace string) func(context.Context, configmap.Watcher) *c
 			}
 		})
 
+		c.enqueueAfter = impl.EnqueueAfter
+
 		logger.Info("Setting up event handlers")
 
 		// Add event handler for Runs
@@ -19,13 +19,16 @@ package tasklooprun
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/google/go-containerregistry/pkg/authn/k8schain"
 	"github.com/hashicorp/go-multierror"
 	"github.com/tektoncd/experimental/task-loops/pkg/apis/taskloop"
 	taskloopv1alpha1 "github.com/tektoncd/experimental/task-loops/pkg/apis/taskloop/v1alpha1"
@@ -34,18 +37,24 @@ import (
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	runv1alpha1 "github.com/tektoncd/pipeline/pkg/apis/run/v1alpha1"
 	clientset "github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
 	runreconciler "github.com/tektoncd/pipeline/pkg/client/injection/reconciler/pipeline/v1alpha1/run"
 	listersalpha "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1alpha1"
 	listers "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1beta1"
 	"github.com/tektoncd/pipeline/pkg/names"
 	"github.com/tektoncd/pipeline/pkg/reconciler/events"
+	"github.com/tektoncd/pipeline/pkg/remote/oci"
 	"go.uber.org/zap"
 	"gomodules.xyz/jsonpatch/v2"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
 	"knative.dev/pkg/apis"
 	"knative.dev/pkg/logging"
 	pkgreconciler "knative.dev/pkg/reconciler"
@@ -60,15 +69,36 @@ const (
 
 	// taskLoopIterationLabelKey is the label identifier for the iteration number.  This label is added to the Run's TaskRuns.
 	taskLoopIterationLabelKey = "/taskLoopIteration"
+
+	// taskLoopCombinationLabelKey is the label identifier for the combination of iterate parameter values.
+	// This label is added to the Run's TaskRuns when the TaskLoop iterates over multiple parameters.
+	taskLoopCombinationLabelKey = "/taskLoopCombination"
+
+	// namespaceConcurrencyRecheckDelay is how long a Run that is held back by a namespace concurrency limit waits
+	// before checking the limit again.  The TaskRuns of other Runs don't trigger a reconcile of the Run.
+	namespaceConcurrencyRecheckDelay = 10 * time.Second
 )
 
+// iterateParam holds the name of an iterate parameter and the values to iterate over.
+// When the values are JSON objects whose fields are mapped onto task parameters,
+// params holds the task parameters for each value.
+type iterateParam struct {
+	name    string
+	values  []string
+	objects []map[string]json.RawMessage
+	params  [][]v1beta1.Param
+}
+
 // Reconciler implements controller.Reconciler for Configuration resources.
 type Reconciler struct {
+	kubeClientSet     kubernetes.Interface
 	pipelineClientSet clientset.Interface
 	taskloopClientSet taskloopclientset.Interface
 	runLister         listersalpha.RunLister
 	taskLoopLister    listerstaskloop.TaskLoopLister
 	taskRunLister     listers.TaskRunLister
+	// enqueueAfter schedules another reconcile of a Run, e.g. when a TaskRun waits for a retry backoff.
+	enqueueAfter func(interface{}, time.Duration)
 }
 
 var (
@@ -178,8 +208,8 @@ func (c *Reconciler) reconcile(ctx context.Context, run *v1alpha1.Run, status *t
 	// Propagate labels and annotations from TaskLoop to Run.
 	propagateTaskLoopLabelsAndAnnotations(run, taskLoopMeta)
 
-	// Validate TaskLoop spec
-	if err := taskLoopSpec.Validate(ctx); err != nil {
+	// Validate TaskLoop spec, including the values that the Run provides for the iterate parameters.
+	if err := taskLoopSpec.Validate(taskloopv1alpha1.WithRunParams(ctx, run.Spec.Params)); err != nil {
 		run.Status.MarkRunFailed(taskloopv1alpha1.TaskLoopRunReasonFailedValidation.String(),
 			"TaskLoop %s/%s can't be Run; it has an invalid spec: %s",
 			taskLoopMeta.Namespace, taskLoopMeta.Name, err)
@@ -187,21 +217,75 @@ func (c *Reconciler) reconcile(ctx context.Context, run *v1alpha1.Run, status *t
 	}
 
 	// Determine how many iterations of the Task will be done.
-	totalIterations, err := computeIterations(run, taskLoopSpec)
+	iterateParams, err := getIterateParams(run, taskLoopSpec)
 	if err != nil {
 		run.Status.MarkRunFailed(taskloopv1alpha1.TaskLoopRunReasonFailedValidation.String(),
 			"Cannot determine number of iterations: %s", err)
 		return nil
 	}
+	totalIterations := computeIterations(iterateParams, taskLoopSpec)
+
+	// Resolve the Task once per Run and store its spec on the Run so that every iteration
+	// runs the same Task, even if a bundle that contains it changes while the Run is in progress.
+	if status.TaskSpec == nil {
+		taskSpec, err := c.getTaskSpec(ctx, run, taskLoopSpec)
+		if err != nil {
+			run.Status.MarkRunFailed(taskloopv1alpha1.TaskLoopRunReasonCouldntGetTask.String(),
+				"Error retrieving Task for Run %s/%s: %s",
+				run.Namespace, run.Name, err)
+			return nil
+		}
+		status.TaskSpec = taskSpec
+	}
+	taskSpec := status.TaskSpec
+
+	// An inline task is validated with the TaskLoop but a referenced task is only known once it's resolved.
+	if err := taskloopv1alpha1.ValidateTaskResults(taskSpec.Results); err != nil {
+		run.Status.MarkRunFailed(taskloopv1alpha1.TaskLoopRunReasonFailedValidation.String(),
+			"TaskLoop %s/%s can't be Run; its task has an invalid spec: %s",
+			taskLoopMeta.Namespace, taskLoopMeta.Name, err)
+		return nil
+	}
+
+	// Map the fields of the JSON objects held by the iterate parameter onto task parameters.
+	if len(taskLoopSpec.IterateFields) != 0 {
+		if err := taskLoopSpec.ValidateIterateFields(taskSpec.Params); err != nil {
+			run.Status.MarkRunFailed(taskloopv1alpha1.TaskLoopRunReasonFailedValidation.String(),
+				"TaskLoop %s/%s can't be Run; it has an invalid spec: %s",
+				taskLoopMeta.Namespace, taskLoopMeta.Name, err)
+			return nil
+		}
+		if err := mapIterateFields(iterateParams, taskLoopSpec, taskSpec.Params); err != nil {
+			run.Status.MarkRunFailed(taskloopv1alpha1.TaskLoopRunReasonFailedValidation.String(),
+				"Cannot map the fields of iterate parameter %q: %s", taskLoopSpec.IterateParam, err)
+			return nil
+		}
+	}
+
+	// Check whether the loop timeout has passed.  If it hasn't, reconcile the Run again when it does.
+	timedOut := false
+	if taskLoopSpec.LoopTimeout != nil && taskLoopSpec.LoopTimeout.Duration > 0 && run.Status.StartTime != nil {
+		remaining := time.Until(run.Status.StartTime.Add(taskLoopSpec.LoopTimeout.Duration))
+		if remaining <= 0 {
+			timedOut = true
+		} else {
+			c.enqueueAfter(run, remaining)
+		}
+	}
 
 	// Update the status of the TaskRuns created from this Run on prior reconciliations.
 	// updateTaskRunStatus() also handles TaskRun cancellation and retry.
 	// It returns the total number of TaskRuns that are running now, the highest
-	// iteration number processed so far, and an indicator whether any TaskRun has failed.
-	totalRunning, highestIteration, taskRunFailed, err := c.updateTaskRunStatus(ctx, logger, run, status, taskLoopSpec)
+	// iteration number processed so far, the iteration numbers of the TaskRuns that have failed,
+	// and the time until the next retry of a TaskRun that waits for a retry backoff.
+	totalRunning, highestIteration, failedIterations, retryAfter, err := c.updateTaskRunStatus(ctx, logger, run, status, taskLoopSpec, timedOut)
 	if err != nil {
 		return fmt.Errorf("error updating TaskRun status for Run %s/%s: %w", run.Namespace, run.Name, err)
 	}
+	if retryAfter > 0 {
+		logger.Infof("Run %s/%s will retry a TaskRun in %s", run.Namespace, run.Name, retryAfter)
+		c.enqueueAfter(run, retryAfter)
+	}
 
 	// Check if the run was cancelled.  Since updateTaskRunStatus() handled cancelling any running TaskRuns
 	// the only thing to do here is to determine if all running TaskRuns have finished.
@@ -218,16 +302,41 @@ func (c *Reconciler) reconcile(ctx context.Context, run *v1alpha1.Run, status *t
 		return nil
 	}
 
+	// Check if the loop timed out.  updateTaskRunStatus() cancelled any running TaskRuns as it does
+	// for a cancelled Run, so the Run fails once they have finished.
+	if timedOut {
+		if totalRunning == 0 {
+			run.Status.MarkRunFailed(taskloopv1alpha1.TaskLoopRunReasonTimedOut.String(),
+				"Run %s/%s timed out after %s", run.Namespace, run.Name, taskLoopSpec.LoopTimeout.Duration)
+		} else {
+			run.Status.MarkRunRunning(taskloopv1alpha1.TaskLoopRunReasonRunning.String(),
+				"Cancelling TaskRuns after the Run timed out")
+		}
+		return nil
+	}
+
 	// Check if the Run is done.
-	//   1) TaskRuns were created for all iterations OR a TaskRun has failed.
-	//      (TaskRun failure stops submission of any remaining iterations.)
+	//   1) TaskRuns were created for all iterations OR the failure policy stops the loop.
+	//      (Depending on the failure policy, TaskRun failure stops submission of any remaining iterations.)
 	//   2) All TaskRuns are done.  If there are TaskRuns running then wait
 	//      for them to complete before marking the Run complete.
-	if highestIteration == totalIterations || taskRunFailed {
+	loopFailed, err := isLoopFailed(taskLoopSpec, len(failedIterations), totalIterations)
+	if err != nil {
+		run.Status.MarkRunFailed(taskloopv1alpha1.TaskLoopRunReasonFailedValidation.String(),
+			"Cannot determine failure threshold: %s", err)
+		return nil
+	}
+	stopLoop := loopFailed && taskLoopSpec.FailurePolicy != taskloopv1alpha1.FailurePolicyRunAll
+	if highestIteration == totalIterations || stopLoop {
 		if totalRunning == 0 {
-			if taskRunFailed {
+			// All TaskRuns are done so their results can be published on the Run.
+			run.Status.Results = getRunResults(status, taskLoopSpec, totalIterations)
+			if loopFailed {
 				run.Status.MarkRunFailed(taskloopv1alpha1.TaskLoopRunReasonFailed.String(),
-					"One or more TaskRuns have failed")
+					"One or more TaskRuns have failed. Failed iterations: %s", formatIterations(failedIterations))
+			} else if len(failedIterations) != 0 {
+				run.Status.MarkRunSucceeded(taskloopv1alpha1.TaskLoopRunReasonSucceeded.String(),
+					"TaskRuns completed with failures below the failure threshold. Failed iterations: %s", formatIterations(failedIterations))
 			} else {
 				run.Status.MarkRunSucceeded(taskloopv1alpha1.TaskLoopRunReasonSucceeded.String(),
 					"All TaskRuns completed successfully")
@@ -248,26 +357,111 @@ func (c *Reconciler) reconcile(ctx context.Context, run *v1alpha1.Run, status *t
 	if taskLoopSpec.Concurrency != nil {
 		concurrency = *taskLoopSpec.Concurrency
 	}
-	for nextIteration <= totalIterations && (concurrency <= 0 || totalRunning < concurrency) {
+	// A namespace concurrency limit is shared with other Runs, so count their running TaskRuns as well.
+	namespaceLimit, namespaceRunning := -1, 0
+	if nc := taskLoopSpec.NamespaceConcurrency; nc != nil && nextIteration <= totalIterations {
+		if selector, ok := getNamespaceConcurrencySelector(run, nc); ok {
+			namespaceLimit, err = c.getNamespaceConcurrencyLimit(ctx, run.Namespace, nc)
+			if err != nil {
+				run.Status.MarkRunFailed(taskloopv1alpha1.TaskLoopRunReasonCouldntGetConcurrencyLimit.String(),
+					"Error retrieving the namespace concurrency limit for Run %s/%s: %s",
+					run.Namespace, run.Name, err)
+				return nil
+			}
+			namespaceRunning, err = c.countRunningTaskRuns(run.Namespace, selector)
+			if err != nil {
+				return fmt.Errorf("error counting the running TaskRuns for Run %s: %w", run.Name, err)
+			}
+		}
+	}
+	for nextIteration <= totalIterations && (concurrency <= 0 || totalRunning < concurrency) && (namespaceLimit < 0 || namespaceRunning < namespaceLimit) {
 		// Create a TaskRun to run the next iteration.
-		tr, err := c.createTaskRun(ctx, logger, taskLoopSpec, run, nextIteration)
+		tr, err := c.createTaskRun(ctx, logger, taskLoopSpec, taskSpec, run, iterateParams, nextIteration)
 		if err != nil {
 			return fmt.Errorf("error creating TaskRun from Run %s: %w", run.Name, err)
 		}
 		status.TaskRuns[tr.Name] = &taskloopv1alpha1.TaskLoopTaskRunStatus{
-			Iteration: nextIteration,
-			Status:    &tr.Status,
+			Iteration:       nextIteration,
+			IterationParams: getIterationParams(tr.Spec.Params, taskLoopSpec),
+			Attempts:        1,
+			Status:          &tr.Status,
 		}
 		totalRunning++
+		namespaceRunning++
 		nextIteration++
 	}
 
+	// If only the namespace concurrency limit holds back the next iteration, check the limit again later.
+	if nextIteration <= totalIterations && (concurrency <= 0 || totalRunning < concurrency) && namespaceLimit >= 0 {
+		logger.Infof("Run %s/%s is waiting for the namespace concurrency limit of %d", run.Namespace, run.Name, namespaceLimit)
+		c.enqueueAfter(run, namespaceConcurrencyRecheckDelay)
+	}
+
 	run.Status.MarkRunRunning(taskloopv1alpha1.TaskLoopRunReasonRunning.String(),
 		"Iterations completed: %d", nextIteration-totalRunning-1)
 
 	return nil
 }
 
+// getNamespaceConcurrencySelector returns the selector for the TaskRuns that share a namespace concurrency limit
+// with the Run.  It returns false if the limit doesn't apply to the Run because the Run doesn't have the label.
+func getNamespaceConcurrencySelector(run *v1alpha1.Run, nc *taskloopv1alpha1.NamespaceConcurrency) (labels.Selector, bool) {
+	selector := labels.NewSelector()
+	r, err := labels.NewRequirement(taskloop.GroupName+taskLoopLabelKey, selection.Exists, nil)
+	if err != nil {
+		return nil, false
+	}
+	selector = selector.Add(*r)
+	if nc.LabelKey != "" {
+		value, ok := run.ObjectMeta.Labels[nc.LabelKey]
+		if !ok {
+			return nil, false
+		}
+		r, err := labels.NewRequirement(nc.LabelKey, selection.Equals, []string{value})
+		if err != nil {
+			return nil, false
+		}
+		selector = selector.Add(*r)
+	}
+	return selector, true
+}
+
+// getNamespaceConcurrencyLimit returns the namespace concurrency limit, reading it from a ConfigMap if necessary.
+func (c *Reconciler) getNamespaceConcurrencyLimit(ctx context.Context, namespace string, nc *taskloopv1alpha1.NamespaceConcurrency) (int, error) {
+	if nc.Limit != nil {
+		return *nc.Limit, nil
+	}
+	ref := nc.ConfigMapKeyRef
+	cm, err := c.kubeClientSet.CoreV1().ConfigMaps(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return 0, err
+	}
+	value, ok := cm.Data[ref.Key]
+	if !ok {
+		return 0, fmt.Errorf("ConfigMap %s has no key %s", ref.Name, ref.Key)
+	}
+	limit, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || limit < 1 {
+		return 0, fmt.Errorf("key %s of ConfigMap %s has an invalid limit %q", ref.Key, ref.Name, value)
+	}
+	return limit, nil
+}
+
+// countRunningTaskRuns returns the number of TaskRuns matching the selector that haven't completed.
+func (c *Reconciler) countRunningTaskRuns(namespace string, selector labels.Selector) (int, error) {
+	trs, err := c.taskRunLister.TaskRuns(namespace).List(selector)
+	if err != nil {
+		return 0, err
+	}
+	running := 0
+	for _, tr := range trs {
+		if !tr.IsDone() {
+			running++
+		}
+	}
+	return running, nil
+}
+
 func (c *Reconciler) getTaskLoop(ctx context.Context, run *v1alpha1.Run) (*metav1.ObjectMeta, *taskloopv1alpha1.TaskLoopSpec, error) {
 	taskLoopMeta := metav1.ObjectMeta{}
 	taskLoopSpec := taskloopv1alpha1.TaskLoopSpec{}
@@ -296,7 +490,66 @@ func (c *Reconciler) getTaskLoop(ctx context.Context, run *v1alpha1.Run) (*metav
 	return &taskLoopMeta, &taskLoopSpec, nil
 }
 
-func (c *Reconciler) createTaskRun(ctx context.Context, logger *zap.SugaredLogger, tls *taskloopv1alpha1.TaskLoopSpec, run *v1alpha1.Run, iteration int) (*v1beta1.TaskRun, error) {
+func (c *Reconciler) getTaskSpec(ctx context.Context, run *v1alpha1.Run, tls *taskloopv1alpha1.TaskLoopSpec) (*v1beta1.TaskSpec, error) {
+	if tls.TaskSpec != nil {
+		return tls.TaskSpec, nil
+	}
+	if tls.TaskRef.Bundle != "" {
+		return c.getBundleTaskSpec(ctx, run, tls.TaskRef)
+	}
+	// Use the k8 client to get the Task rather than a lister for the same reason as getTaskLoop().
+	if tls.TaskRef.Kind == v1beta1.ClusterTaskKind {
+		ct, err := c.pipelineClientSet.TektonV1beta1().ClusterTasks().Get(ctx, tls.TaskRef.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return &ct.Spec, nil
+	}
+	t, err := c.pipelineClientSet.TektonV1beta1().Tasks(run.Namespace).Get(ctx, tls.TaskRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &t.Spec, nil
+}
+
+// getBundleTaskSpec fetches the Task or ClusterTask referenced by a taskRef from a Tekton bundle.
+// The bundle is pulled with the credentials of the Run's service account and image pull secrets,
+// as Tekton does for the bundles referenced by TaskRuns.
+func (c *Reconciler) getBundleTaskSpec(ctx context.Context, run *v1alpha1.Run, taskRef *v1beta1.TaskRef) (*v1beta1.TaskSpec, error) {
+	kind := v1beta1.NamespacedTaskKind
+	if taskRef.Kind != "" {
+		kind = taskRef.Kind
+	}
+	var imagePullSecrets []string
+	if run.Spec.PodTemplate != nil {
+		for _, s := range run.Spec.PodTemplate.ImagePullSecrets {
+			imagePullSecrets = append(imagePullSecrets, s.Name)
+		}
+	}
+	kc, err := k8schain.New(ctx, c.kubeClientSet, k8schain.Options{
+		Namespace:          run.Namespace,
+		ServiceAccountName: run.Spec.ServiceAccountName,
+		ImagePullSecrets:   imagePullSecrets,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get keychain: %w", err)
+	}
+	resolver := oci.NewResolver(taskRef.Bundle, kc)
+	obj, err := resolver.Get(strings.ToLower(string(kind)), taskRef.Name)
+	if err != nil {
+		return nil, err
+	}
+	task, ok := obj.(v1beta1.TaskObject)
+	if !ok {
+		return nil, fmt.Errorf("bundle %s contains %s %s which is not a v1beta1 %s",
+			taskRef.Bundle, obj.GetObjectKind().GroupVersionKind(), taskRef.Name, kind)
+	}
+	taskSpec := task.TaskSpec()
+	return &taskSpec, nil
+}
+
+func (c *Reconciler) createTaskRun(ctx context.Context, logger *zap.SugaredLogger, tls *taskloopv1alpha1.TaskLoopSpec, taskSpec *v1beta1.TaskSpec, run *v1alpha1.Run,
+	iterateParams []iterateParam, iteration int) (*v1beta1.TaskRun, error) {
 
 	// Create name for TaskRun from Run name plus iteration number.
 	trName := names.SimpleNameGenerator.RestrictLengthWithRandomSuffix(fmt.Sprintf("%s-%s", run.Name, fmt.Sprintf("%05d", iteration)))
@@ -310,22 +563,22 @@ func (c *Reconciler) createTaskRun(ctx context.Context, logger *zap.SugaredLogge
 			Annotations:     getTaskRunAnnotations(run),
 		},
 		Spec: v1beta1.TaskRunSpec{
-			Params:             getParameters(run, tls, iteration),
+			Params:             getParameters(run, tls, iterateParams, iteration),
 			Timeout:            tls.Timeout,
 			ServiceAccountName: run.Spec.ServiceAccountName,
 			PodTemplate:        run.Spec.PodTemplate,
 			Workspaces:         run.Spec.Workspaces,
 		}}
 
-	if tls.TaskRef != nil {
-		tr.Spec.TaskRef = &v1beta1.TaskRef{
-			Name: tls.TaskRef.Name,
-			Kind: tls.TaskRef.Kind,
-		}
-	} else if tls.TaskSpec != nil {
-		tr.Spec.TaskSpec = tls.TaskSpec
+	// Record the combination of iterate parameter values when iterating over a matrix of parameters.
+	if len(iterateParams) > 1 && tls.IterationMode != taskloopv1alpha1.IterationModeZip {
+		tr.ObjectMeta.Labels[taskloop.GroupName+taskLoopCombinationLabelKey] = getCombinationLabel(iterateParams, tls, iteration)
 	}
 
+	// The Task resolved for the Run is embedded so that every iteration runs the same Task,
+	// even if the Task or the bundle that contains it changes while the Run is in progress.
+	tr.Spec.TaskSpec = taskSpec
+
 	logger.Infof("Creating a new TaskRun object %s", trName)
 	return c.pipelineClientSet.TektonV1beta1().TaskRuns(run.Namespace).Create(ctx, tr, metav1.CreateOptions{})
 
@@ -368,7 +621,7 @@ func (c *Reconciler) updateLabelsAndAnnotations(ctx context.Context, run *v1alph
 }
 
 func (c *Reconciler) updateTaskRunStatus(ctx context.Context, logger *zap.SugaredLogger, run *v1alpha1.Run, status *taskloopv1alpha1.TaskLoopRunStatus,
-	taskLoopSpec *taskloopv1alpha1.TaskLoopSpec) (totalRunning int, highestIteration int, taskRunFailed bool, retryableErr error) {
+	taskLoopSpec *taskloopv1alpha1.TaskLoopSpec, timedOut bool) (totalRunning int, highestIteration int, failedIterations []int, retryAfter time.Duration, retryableErr error) {
 	if status.TaskRuns == nil {
 		status.TaskRuns = make(map[string]*taskloopv1alpha1.TaskLoopTaskRunStatus)
 	}
@@ -397,8 +650,10 @@ func (c *Reconciler) updateTaskRunStatus(ctx context.Context, logger *zap.Sugare
 			return
 		}
 		status.TaskRuns[tr.Name] = &taskloopv1alpha1.TaskLoopTaskRunStatus{
-			Iteration: iteration,
-			Status:    &tr.Status,
+			Iteration:       iteration,
+			IterationParams: getIterationParams(tr.Spec.Params, taskLoopSpec),
+			Attempts:        len(tr.Status.RetriesStatus) + 1,
+			Status:          &tr.Status,
 		}
 		// If the TaskRun was created before the Run says it was started, then change the Run's
 		// start time.  This happens when this reconcile call has been passed stale status that
@@ -409,7 +664,8 @@ func (c *Reconciler) updateTaskRunStatus(ctx context.Context, logger *zap.Sugare
 			run.Status.CompletionTime = tr.CreationTimestamp.DeepCopy()
 		}
 		// Handle TaskRun cancellation and retry.
-		if err := c.processTaskRun(ctx, logger, tr, run, status, taskLoopSpec); err != nil {
+		trRetryAfter, err := c.processTaskRun(ctx, logger, tr, run, status, taskLoopSpec, timedOut)
+		if err != nil {
 			retryableErr = fmt.Errorf("error processing TaskRun %s: %#v", tr.Name, err)
 			return
 		}
@@ -418,80 +674,357 @@ func (c *Reconciler) updateTaskRunStatus(ctx context.Context, logger *zap.Sugare
 		}
 		if !tr.IsDone() {
 			totalRunning++
+		} else if trRetryAfter > 0 {
+			// A TaskRun that waits to be retried is still running as far as the loop is concerned.
+			totalRunning++
+			if retryAfter == 0 || trRetryAfter < retryAfter {
+				retryAfter = trRetryAfter
+			}
 		} else {
 			if !tr.IsSuccessful() {
-				taskRunFailed = true
+				failedIterations = append(failedIterations, iteration)
 			}
 		}
 	}
+	sort.Ints(failedIterations)
 	return
 }
 
 func (c *Reconciler) processTaskRun(ctx context.Context, logger *zap.SugaredLogger, tr *v1beta1.TaskRun,
-	run *v1alpha1.Run, status *taskloopv1alpha1.TaskLoopRunStatus, taskLoopSpec *taskloopv1alpha1.TaskLoopSpec) error {
-	// If the TaskRun is running and the Run is cancelled, cancel the TaskRun.
+	run *v1alpha1.Run, status *taskloopv1alpha1.TaskLoopRunStatus, taskLoopSpec *taskloopv1alpha1.TaskLoopSpec, timedOut bool) (time.Duration, error) {
+	// If the TaskRun is running and the Run is cancelled or timed out, cancel the TaskRun.
 	if !tr.IsDone() {
-		if run.IsCancelled() && !tr.IsCancelled() {
-			logger.Infof("Run %s/%s is cancelled.  Cancelling TaskRun %s.", run.Namespace, run.Name, tr.Name)
+		if (run.IsCancelled() || timedOut) && !tr.IsCancelled() {
+			logger.Infof("Run %s/%s is cancelled or timed out.  Cancelling TaskRun %s.", run.Namespace, run.Name, tr.Name)
 			if _, err := c.pipelineClientSet.TektonV1beta1().TaskRuns(run.Namespace).Patch(ctx, tr.Name, types.JSONPatchType, cancelPatchBytes, metav1.PatchOptions{}); err != nil {
-				return fmt.Errorf("Failed to patch TaskRun `%s` with cancellation: %v", tr.Name, err)
+				return 0, fmt.Errorf("Failed to patch TaskRun `%s` with cancellation: %v", tr.Name, err)
 			}
 		}
 	} else {
 		// If the TaskRun failed, then retry it if possible.
-		if !tr.IsSuccessful() && !run.IsCancelled() {
+		if !tr.IsSuccessful() && !run.IsCancelled() && !timedOut {
 			retriesDone := len(tr.Status.RetriesStatus)
 			retries := taskLoopSpec.Retries
 			if retriesDone < retries {
+				// With a retry backoff, wait until the delay since the failure has passed.
+				if taskLoopSpec.RetryBackoff != nil && tr.Status.CompletionTime != nil {
+					nextRetryTime := tr.Status.CompletionTime.Add(taskLoopSpec.RetryBackoff.Delay(retriesDone + 1))
+					if wait := time.Until(nextRetryTime); wait > 0 {
+						status.TaskRuns[tr.Name].NextRetryTime = &metav1.Time{Time: nextRetryTime}
+						return wait, nil
+					}
+				}
 				retryTr, err := c.retryTaskRun(ctx, tr)
 				if err != nil {
-					return fmt.Errorf("error retrying TaskRun %s from Run %s: %w", tr.Name, run.Name, err)
+					return 0, fmt.Errorf("error retrying TaskRun %s from Run %s: %w", tr.Name, run.Name, err)
 				}
 				status.TaskRuns[retryTr.Name] = &taskloopv1alpha1.TaskLoopTaskRunStatus{
-					Iteration: status.TaskRuns[retryTr.Name].Iteration,
-					Status:    &retryTr.Status,
+					Iteration:       status.TaskRuns[retryTr.Name].Iteration,
+					IterationParams: status.TaskRuns[retryTr.Name].IterationParams,
+					Attempts:        len(retryTr.Status.RetriesStatus) + 1,
+					Status:          &retryTr.Status,
+				}
+			}
+		}
+	}
+	return 0, nil
+}
+
+// getIterateParamNames returns the names of the iterate parameters whose values are provided by the Run.
+func getIterateParamNames(tls *taskloopv1alpha1.TaskLoopSpec) []string {
+	if len(tls.IterateParams) != 0 {
+		return tls.IterateParams
+	}
+	if tls.IterateParam == "" && tls.IterateNumeric != nil {
+		return nil
+	}
+	return []string{tls.IterateParam}
+}
+
+func getIterateParams(run *v1alpha1.Run, tls *taskloopv1alpha1.TaskLoopSpec) ([]iterateParam, error) {
+	// Find the iterate parameters.
+	names := getIterateParamNames(tls)
+	out := make([]iterateParam, 0, len(names)+1)
+	for _, name := range names {
+		found := false
+		for _, p := range run.Spec.Params {
+			if p.Name != name {
+				continue
+			}
+			ip := iterateParam{name: name}
+			if name == tls.IterateParam && len(tls.IterateFields) != 0 {
+				objects, err := taskloopv1alpha1.IterateObjects(p.Value)
+				if err != nil {
+					return nil, fmt.Errorf("The iterate parameter %q is invalid: %w", name, err)
+				}
+				ip.objects = objects
+				ip.values = make([]string, len(objects))
+				for i, object := range objects {
+					b, err := json.Marshal(object)
+					if err != nil {
+						return nil, err
+					}
+					ip.values[i] = string(b)
 				}
+			} else {
+				ip.values = taskloopv1alpha1.IterateValues(p.Value)
 			}
+			out = append(out, ip)
+			found = true
+			break
+		}
+		if !found {
+			return nil, fmt.Errorf("The iterate parameter %q was not found", name)
 		}
 	}
+	// Add the values of the numeric range.
+	if tls.IterateNumeric != nil {
+		values, err := tls.IterateNumeric.Values(run.Spec.Params)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, iterateParam{name: tls.IterateNumeric.Param, values: values})
+	}
+	return out, nil
+}
+
+// mapIterateFields computes the task parameters for each JSON object held by the iterate parameter.
+// A field that is missing from an object is left out if the task parameter has a default value.
+func mapIterateFields(iterateParams []iterateParam, tls *taskloopv1alpha1.TaskLoopSpec, paramSpecs []v1beta1.ParamSpec) error {
+	for i, ip := range iterateParams {
+		if ip.objects == nil {
+			continue
+		}
+		params := make([][]v1beta1.Param, len(ip.objects))
+		for j, object := range ip.objects {
+			for _, f := range tls.IterateFields {
+				var paramSpec v1beta1.ParamSpec
+				for _, ps := range paramSpecs {
+					if ps.Name == f.Param {
+						paramSpec = ps
+					}
+				}
+				raw, ok := object[f.Field]
+				if !ok {
+					if paramSpec.Default != nil {
+						continue
+					}
+					return fmt.Errorf("item %d has no field %q", j+1, f.Field)
+				}
+				value, err := getFieldValue(raw, paramSpec.Type)
+				if err != nil {
+					return fmt.Errorf("item %d field %q: %w", j+1, f.Field, err)
+				}
+				params[j] = append(params[j], v1beta1.Param{Name: f.Param, Value: value})
+			}
+		}
+		iterateParams[i].params = params
+	}
 	return nil
 }
 
-func computeIterations(run *v1alpha1.Run, tls *taskloopv1alpha1.TaskLoopSpec) (int, error) {
-	// Find the iterate parameter.
-	numberOfIterations := -1
+// getFieldValue converts the JSON value of a field to a value for a task parameter of the given type.
+// A string parameter accepts a string, number or boolean.  An array parameter accepts an array of strings.
+func getFieldValue(raw json.RawMessage, paramType v1beta1.ParamType) (v1beta1.ArrayOrString, error) {
+	if paramType == v1beta1.ParamTypeArray {
+		var values []string
+		if err := json.Unmarshal(raw, &values); err != nil {
+			return v1beta1.ArrayOrString{}, errors.New("value must be an array of strings")
+		}
+		return v1beta1.ArrayOrString{Type: v1beta1.ParamTypeArray, ArrayVal: values}, nil
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err == nil {
+		return v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: value}, nil
+	}
+	var scalar interface{}
+	if err := json.Unmarshal(raw, &scalar); err != nil {
+		return v1beta1.ArrayOrString{}, err
+	}
+	switch scalar.(type) {
+	case float64, bool:
+		return v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: string(raw)}, nil
+	default:
+		return v1beta1.ArrayOrString{}, errors.New("value must be a string, number or boolean")
+	}
+}
+
+func computeIterations(iterateParams []iterateParam, tls *taskloopv1alpha1.TaskLoopSpec) int {
+	// In zip mode the iterate parameters have the same number of values and are iterated in lockstep.
+	if tls.IterationMode == taskloopv1alpha1.IterationModeZip {
+		return len(iterateParams[0].values)
+	}
+	// Otherwise one iteration is done for each combination of the iterate parameter values.
+	numberOfIterations := 1
+	for _, ip := range iterateParams {
+		numberOfIterations *= len(ip.values)
+	}
+	return numberOfIterations
+}
+
+// getCombination returns the index into the values of each iterate parameter for an iteration.
+// In matrix mode iterations enumerate the combinations with the last iterate parameter varying fastest.
+func getCombination(iterateParams []iterateParam, tls *taskloopv1alpha1.TaskLoopSpec, iteration int) []int {
+	indices := make([]int, len(iterateParams))
+	if tls.IterationMode == taskloopv1alpha1.IterationModeZip {
+		for i := range indices {
+			indices[i] = iteration - 1
+		}
+		return indices
+	}
+	remainder := iteration - 1
+	for i := len(iterateParams) - 1; i >= 0; i-- {
+		n := len(iterateParams[i].values)
+		indices[i] = remainder % n
+		remainder /= n
+	}
+	return indices
+}
+
+func getCombinationLabel(iterateParams []iterateParam, tls *taskloopv1alpha1.TaskLoopSpec, iteration int) string {
+	// The label value lists the 1-based position of each iterate parameter value, e.g. "2-1-3".
+	indices := getCombination(iterateParams, tls, iteration)
+	positions := make([]string, len(indices))
+	for i, index := range indices {
+		positions[i] = strconv.Itoa(index + 1)
+	}
+	return strings.Join(positions, "-")
+}
+
+func getParameters(run *v1alpha1.Run, tls *taskloopv1alpha1.TaskLoopSpec, iterateParams []iterateParam, iteration int) []v1beta1.Param {
+	indices := getCombination(iterateParams, tls, iteration)
+	out := make([]v1beta1.Param, 0, len(run.Spec.Params)+len(tls.IterateFields)+1)
+	found := make([]bool, len(iterateParams))
 	for _, p := range run.Spec.Params {
-		if p.Name == tls.IterateParam {
-			if p.Value.Type == v1beta1.ParamTypeString {
-				// If we got a string param, split it into an array, one item per line
-				p.Value.ArrayVal = strings.Split(strings.TrimSuffix(p.Value.StringVal, "\n"), "\n")
+		param := []v1beta1.Param{p}
+		for j, ip := range iterateParams {
+			if p.Name != ip.name {
+				continue
+			}
+			if ip.params != nil {
+				// The fields of the JSON object are passed instead of the iterate parameter.
+				param = ip.params[indices[j]]
+			} else {
+				param = []v1beta1.Param{{
+					Name:  p.Name,
+					Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: ip.values[indices[j]]},
+				}}
 			}
-			numberOfIterations = len(p.Value.ArrayVal)
+			found[j] = true
 		}
+		out = append(out, param...)
 	}
-	if numberOfIterations == -1 {
-		return 0, fmt.Errorf("The iterate parameter %q was not found", tls.IterateParam)
+	// Add the iterate parameters that the Run doesn't provide, i.e. the numeric range.
+	for j, ip := range iterateParams {
+		if !found[j] {
+			out = append(out, v1beta1.Param{
+				Name:  ip.name,
+				Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: ip.values[indices[j]]},
+			})
+		}
 	}
-	return numberOfIterations, nil
+	return out
 }
 
-func getParameters(run *v1alpha1.Run, tls *taskloopv1alpha1.TaskLoopSpec, iteration int) []v1beta1.Param {
-	out := make([]v1beta1.Param, len(run.Spec.Params))
-	for i, p := range run.Spec.Params {
-		if p.Name == tls.IterateParam {
-			if p.Value.Type == v1beta1.ParamTypeString {
-				// If we got a string param, split it into an array, one item per line
-				p.Value.ArrayVal = strings.Split(strings.TrimSuffix(p.Value.StringVal, "\n"), "\n")
+func getIterationParams(params []v1beta1.Param, tls *taskloopv1alpha1.TaskLoopSpec) []v1beta1.Param {
+	// Pick the iterate parameters out of the parameters passed to a TaskRun.
+	names := append([]string{}, getIterateParamNames(tls)...)
+	for _, f := range tls.IterateFields {
+		names = append(names, f.Param)
+	}
+	if tls.IterateNumeric != nil {
+		names = append(names, tls.IterateNumeric.Param)
+	}
+	var out []v1beta1.Param
+	for _, name := range names {
+		for _, p := range params {
+			if p.Name == name {
+				out = append(out, p)
 			}
-			out[i] = v1beta1.Param{
-				Name:  p.Name,
-				Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: p.Value.ArrayVal[iteration-1]},
+		}
+	}
+	return out
+}
+
+// isLoopFailed determines whether enough iterations have failed for the Run to fail.
+func isLoopFailed(tls *taskloopv1alpha1.TaskLoopSpec, failedCount int, totalIterations int) (bool, error) {
+	if tls.FailurePolicy == taskloopv1alpha1.FailurePolicyThreshold {
+		// A percentage threshold is rounded up so that a Run doesn't fail before the percentage is reached.
+		threshold, err := intstr.GetValueFromIntOrPercent(tls.FailureThreshold, totalIterations, true)
+		if err != nil {
+			return false, err
+		}
+		return failedCount > 0 && failedCount >= threshold, nil
+	}
+	return failedCount > 0, nil
+}
+
+// getRunResults collects the results of the TaskRuns into Run results.
+// Iterations that failed or were never run are marked as null in array results.
+// In either format the failed iterations are listed in an additional result.
+func getRunResults(status *taskloopv1alpha1.TaskLoopRunStatus, tls *taskloopv1alpha1.TaskLoopSpec, totalIterations int) []runv1alpha1.RunResult {
+	// Index the TaskRuns by iteration and find the names of all results declared or produced by them.
+	taskRunsByIteration := make(map[int]*taskloopv1alpha1.TaskLoopTaskRunStatus, len(status.TaskRuns))
+	resultNames := sets.NewString()
+	for _, trs := range status.TaskRuns {
+		if trs.Status == nil {
+			continue
+		}
+		taskRunsByIteration[trs.Iteration] = trs
+		if trs.Status.TaskSpec != nil {
+			for _, r := range trs.Status.TaskSpec.Results {
+				resultNames.Insert(r.Name)
+			}
+		}
+		for _, r := range trs.Status.TaskRunResults {
+			resultNames.Insert(r.Name)
+		}
+	}
+
+	// Collect the value of each result for each successful iteration.
+	values := make(map[string][]*string, resultNames.Len())
+	for _, name := range resultNames.List() {
+		values[name] = make([]*string, totalIterations)
+	}
+	failedIterations := []int{}
+	for iteration := 1; iteration <= totalIterations; iteration++ {
+		trs, ok := taskRunsByIteration[iteration]
+		if !ok {
+			continue
+		}
+		if trs.Status.GetCondition(apis.ConditionSucceeded).IsFalse() {
+			failedIterations = append(failedIterations, iteration)
+			continue
+		}
+		for _, r := range trs.Status.TaskRunResults {
+			value := r.Value
+			values[r.Name][iteration-1] = &value
+		}
+	}
+
+	var results []runv1alpha1.RunResult
+	for _, name := range resultNames.List() {
+		if tls.ResultsFormat == taskloopv1alpha1.ResultsFormatPerIteration {
+			for i, value := range values[name] {
+				if value != nil {
+					results = append(results, runv1alpha1.RunResult{Name: fmt.Sprintf("%s-%d", name, i+1), Value: *value})
+				}
 			}
 		} else {
-			out[i] = run.Spec.Params[i]
+			b, _ := json.Marshal(values[name])
+			results = append(results, runv1alpha1.RunResult{Name: name, Value: string(b)})
 		}
 	}
-	return out
+	b, _ := json.Marshal(failedIterations)
+	return append(results, runv1alpha1.RunResult{Name: taskloopv1alpha1.FailedIterationsResultName, Value: string(b)})
+}
+
+func formatIterations(iterations []int) string {
+	s := make([]string, len(iterations))
+	for i, iteration := range iterations {
+		s[i] = strconv.Itoa(iteration)
+	}
+	return strings.Join(s, ", ")
 }
 
 func getTaskRunAnnotations(run *v1alpha1.Run) map[string]string {
@@ -19,12 +19,15 @@ package tasklooprun
 import (
 	"context"
 	"fmt"
+	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/go-containerregistry/pkg/registry"
 	"github.com/tektoncd/experimental/task-loops/pkg/apis/taskloop"
 	taskloopv1alpha1 "github.com/tektoncd/experimental/task-loops/pkg/apis/taskloop/v1alpha1"
 	fakeclient "github.com/tektoncd/experimental/task-loops/pkg/client/injection/client/fake"
@@ -33,13 +36,16 @@ import (
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/pod"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	runv1alpha1 "github.com/tektoncd/pipeline/pkg/apis/run/v1alpha1"
 	ttesting "github.com/tektoncd/pipeline/pkg/reconciler/testing"
 	"github.com/tektoncd/pipeline/pkg/system"
+	ptest "github.com/tektoncd/pipeline/test"
 	"github.com/tektoncd/pipeline/test/diff"
 	"github.com/tektoncd/pipeline/test/names"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	ktesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/record"
 	"knative.dev/pkg/apis"
@@ -63,8 +69,10 @@ var (
 		Name:     "myworkspace",
 		EmptyDir: &corev1.EmptyDirVolumeSource{},
 	}}
-	namespace = ""
-	trueB     = true
+	namespace            = ""
+	trueB                = true
+	failureThreshold2    = intstr.FromInt(2)
+	failureThreshold50pc = intstr.FromString("50%")
 )
 
 func getRunName(run *v1alpha1.Run) string {
@@ -84,18 +92,69 @@ func requestCancel(run *v1alpha1.Run) *v1alpha1.Run {
 	return runWithCancelStatus
 }
 
+func startedAt(run *v1alpha1.Run, startTime time.Time) *v1alpha1.Run {
+	runWithStartTime := run.DeepCopy()
+	runWithStartTime.Status.StartTime = &metav1.Time{Time: startTime}
+	return runWithStartTime
+}
+
+func withLoopTimeout(tl *taskloopv1alpha1.TaskLoop, loopTimeout time.Duration) *taskloopv1alpha1.TaskLoop {
+	taskLoopWithLoopTimeout := tl.DeepCopy()
+	taskLoopWithLoopTimeout.Spec.LoopTimeout = &metav1.Duration{Duration: loopTimeout}
+	return taskLoopWithLoopTimeout
+}
+
 func allowRetry(tl *taskloopv1alpha1.TaskLoop) *taskloopv1alpha1.TaskLoop {
 	taskLoopWithRetries := tl.DeepCopy()
 	taskLoopWithRetries.Spec.Retries = 1
 	return taskLoopWithRetries
 }
 
+func withRetryBackoff(tl *taskloopv1alpha1.TaskLoop, initialDelay time.Duration) *taskloopv1alpha1.TaskLoop {
+	taskLoopWithRetryBackoff := tl.DeepCopy()
+	taskLoopWithRetryBackoff.Spec.RetryBackoff = &taskloopv1alpha1.RetryBackoff{
+		InitialDelay: &metav1.Duration{Duration: initialDelay},
+	}
+	return taskLoopWithRetryBackoff
+}
+
 func withConcurrencyLimit(tl *taskloopv1alpha1.TaskLoop, concurrencyLimit int) *taskloopv1alpha1.TaskLoop {
 	taskLoopWithConcurrency := tl.DeepCopy()
 	taskLoopWithConcurrency.Spec.Concurrency = &concurrencyLimit
 	return taskLoopWithConcurrency
 }
 
+func withNamespaceConcurrency(tl *taskloopv1alpha1.TaskLoop, nc *taskloopv1alpha1.NamespaceConcurrency) *taskloopv1alpha1.TaskLoop {
+	taskLoopWithNamespaceConcurrency := tl.DeepCopy()
+	taskLoopWithNamespaceConcurrency.Spec.NamespaceConcurrency = nc
+	return taskLoopWithNamespaceConcurrency
+}
+
+func withIterationMode(tl *taskloopv1alpha1.TaskLoop, mode taskloopv1alpha1.IterationMode) *taskloopv1alpha1.TaskLoop {
+	taskLoopWithMode := tl.DeepCopy()
+	taskLoopWithMode.Spec.IterationMode = mode
+	return taskLoopWithMode
+}
+
+func withFailurePolicy(tl *taskloopv1alpha1.TaskLoop, policy taskloopv1alpha1.FailurePolicy, threshold *intstr.IntOrString) *taskloopv1alpha1.TaskLoop {
+	taskLoopWithFailurePolicy := tl.DeepCopy()
+	taskLoopWithFailurePolicy.Spec.FailurePolicy = policy
+	taskLoopWithFailurePolicy.Spec.FailureThreshold = threshold
+	return taskLoopWithFailurePolicy
+}
+
+func withResultsFormat(tl *taskloopv1alpha1.TaskLoop, format taskloopv1alpha1.ResultsFormat) *taskloopv1alpha1.TaskLoop {
+	taskLoopWithResultsFormat := tl.DeepCopy()
+	taskLoopWithResultsFormat.Spec.ResultsFormat = format
+	return taskLoopWithResultsFormat
+}
+
+func withResult(tr *v1beta1.TaskRun, name, value string) *v1beta1.TaskRun {
+	trWithResult := tr.DeepCopy()
+	trWithResult.Status.TaskRunResults = append(trWithResult.Status.TaskRunResults, v1beta1.TaskRunResult{Name: name, Value: value})
+	return trWithResult
+}
+
 func running(tr *v1beta1.TaskRun) *v1beta1.TaskRun {
 	trWithStatus := tr.DeepCopy()
 	trWithStatus.Status.SetCondition(&apis.Condition{
@@ -128,10 +187,18 @@ func failed(tr *v1beta1.TaskRun) *v1beta1.TaskRun {
 	return trWithStatus
 }
 
+func completedAt(tr *v1beta1.TaskRun, completionTime time.Time) *v1beta1.TaskRun {
+	trWithCompletionTime := tr.DeepCopy()
+	// The time is truncated because the Run status doesn't keep times with a finer precision.
+	trWithCompletionTime.Status.CompletionTime = &metav1.Time{Time: completionTime.Truncate(time.Second)}
+	return trWithCompletionTime
+}
+
 func retrying(tr *v1beta1.TaskRun) *v1beta1.TaskRun {
 	trWithRetryStatus := tr.DeepCopy()
 	trWithRetryStatus.Status.RetriesStatus = nil
 	trWithRetryStatus.Status.RetriesStatus = append(tr.Status.RetriesStatus, trWithRetryStatus.Status)
+	trWithRetryStatus.Status.CompletionTime = nil
 	trWithRetryStatus.Status.SetCondition(&apis.Condition{
 		Type:   apis.ConditionSucceeded,
 		Status: corev1.ConditionUnknown,
@@ -277,6 +344,13 @@ func checkRunStatus(t *testing.T, run *v1alpha1.Run, expectedStatus map[string]t
 					t.Errorf("Run status for TaskRun %s has iteration number %d instead of %d",
 						actualTaskRunName, actualTaskRunStatus.Iteration, expectedTaskRunStatus.Iteration)
 				}
+				if actualTaskRunStatus.Attempts != expectedTaskRunStatus.Attempts {
+					t.Errorf("Run status for TaskRun %s has %d attempts instead of %d",
+						actualTaskRunName, actualTaskRunStatus.Attempts, expectedTaskRunStatus.Attempts)
+				}
+				if d := cmp.Diff(expectedTaskRunStatus.IterationParams, actualTaskRunStatus.IterationParams); d != "" {
+					t.Errorf("Run status for TaskRun %s has incorrect iteration parameters. Diff %s", actualTaskRunName, diff.PrintWantGot(d))
+				}
 				if d := cmp.Diff(expectedTaskRunStatus.Status, actualTaskRunStatus.Status, cmpopts.IgnoreFields(apis.Condition{}, "LastTransitionTime.Inner.Time")); d != "" {
 					t.Errorf("Run status for TaskRun %s is incorrect. Diff %s", actualTaskRunName, diff.PrintWantGot(d))
 				}
@@ -367,6 +441,138 @@ var runTaskLoop = &v1alpha1.Run{
 	},
 }
 
+var aTaskLoopWithMatrix = &taskloopv1alpha1.TaskLoop{
+	ObjectMeta: metav1.ObjectMeta{
+		Name:      "a-taskloop-with-matrix",
+		Namespace: "foo",
+	},
+	Spec: taskloopv1alpha1.TaskLoopSpec{
+		TaskRef:       &v1beta1.TaskRef{Name: "a-task"},
+		IterateParams: []string{"current-item", "additional-parameter"},
+	},
+}
+
+var runTaskLoopWithMatrix = &v1alpha1.Run{
+	ObjectMeta: metav1.ObjectMeta{
+		Name:      "run-taskloop-with-matrix",
+		Namespace: "foo",
+	},
+	Spec: v1alpha1.RunSpec{
+		Params: []v1beta1.Param{{
+			Name:  "current-item",
+			Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeArray, ArrayVal: []string{"item1", "item2"}},
+		}, {
+			Name:  "additional-parameter",
+			Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "stuff\nthings\n"},
+		}},
+		Ref: &v1alpha1.TaskRef{
+			APIVersion: taskloopv1alpha1.SchemeGroupVersion.String(),
+			Kind:       taskloop.TaskLoopControllerName,
+			Name:       "a-taskloop-with-matrix",
+		},
+	},
+}
+
+var aTaskLoopWithNumericRange = &taskloopv1alpha1.TaskLoop{
+	ObjectMeta: metav1.ObjectMeta{
+		Name:      "a-taskloop-with-numeric-range",
+		Namespace: "foo",
+	},
+	Spec: taskloopv1alpha1.TaskLoopSpec{
+		TaskRef: &v1beta1.TaskRef{Name: "a-task"},
+		IterateNumeric: &taskloopv1alpha1.IterateNumeric{
+			Param: "current-item",
+			From:  "2",
+			To:    "$(params.last-item)",
+			Step:  "2",
+		},
+	},
+}
+
+var runTaskLoopWithNumericRange = &v1alpha1.Run{
+	ObjectMeta: metav1.ObjectMeta{
+		Name:      "run-taskloop-with-numeric-range",
+		Namespace: "foo",
+	},
+	Spec: v1alpha1.RunSpec{
+		Params: []v1beta1.Param{{
+			Name:  "last-item",
+			Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "7"},
+		}, {
+			Name:  "additional-parameter",
+			Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "stuff"},
+		}},
+		Ref: &v1alpha1.TaskRef{
+			APIVersion: taskloopv1alpha1.SchemeGroupVersion.String(),
+			Kind:       taskloop.TaskLoopControllerName,
+			Name:       "a-taskloop-with-numeric-range",
+		},
+	},
+}
+
+var aTaskWithObjectFields = &v1beta1.Task{
+	ObjectMeta: metav1.ObjectMeta{Name: "a-task-with-object-fields", Namespace: "foo"},
+	Spec: v1beta1.TaskSpec{
+		Params: []v1beta1.ParamSpec{{
+			Name: "service",
+			Type: v1beta1.ParamTypeString,
+		}, {
+			Name:    "cluster",
+			Type:    v1beta1.ParamTypeString,
+			Default: &v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "us-east"},
+		}, {
+			Name:    "replicas",
+			Type:    v1beta1.ParamTypeString,
+			Default: &v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "1"},
+		}, {
+			Name:    "ports",
+			Type:    v1beta1.ParamTypeArray,
+			Default: &v1beta1.ArrayOrString{Type: v1beta1.ParamTypeArray, ArrayVal: []string{}},
+		}},
+		Steps: []v1beta1.Step{{
+			Container: corev1.Container{Name: "foo", Image: "bar"},
+		}},
+	},
+}
+
+var aTaskLoopWithObjectFields = &taskloopv1alpha1.TaskLoop{
+	ObjectMeta: metav1.ObjectMeta{
+		Name:      "a-taskloop-with-object-fields",
+		Namespace: "foo",
+	},
+	Spec: taskloopv1alpha1.TaskLoopSpec{
+		TaskRef:      &v1beta1.TaskRef{Name: "a-task-with-object-fields"},
+		IterateParam: "deployments",
+		IterateFields: []taskloopv1alpha1.IterateField{
+			{Field: "service", Param: "service"},
+			{Field: "region", Param: "cluster"},
+			{Field: "replicas", Param: "replicas"},
+			{Field: "ports", Param: "ports"},
+		},
+	},
+}
+
+var runTaskLoopWithObjectFields = &v1alpha1.Run{
+	ObjectMeta: metav1.ObjectMeta{
+		Name:      "run-taskloop-with-object-fields",
+		Namespace: "foo",
+	},
+	Spec: v1alpha1.RunSpec{
+		Params: []v1beta1.Param{{
+			Name: "deployments",
+			Value: v1beta1.ArrayOrString{
+				Type:      v1beta1.ParamTypeString,
+				StringVal: `[{"service":"api","region":"us-west","replicas":3,"ports":["80","443"]},{"service":"web"}]`,
+			},
+		}},
+		Ref: &v1alpha1.TaskRef{
+			APIVersion: taskloopv1alpha1.SchemeGroupVersion.String(),
+			Kind:       taskloop.TaskLoopControllerName,
+			Name:       "a-taskloop-with-object-fields",
+		},
+	},
+}
+
 var runTaskLoopWithInlineTask = &v1alpha1.Run{
 	ObjectMeta: metav1.ObjectMeta{
 		Name:      "run-taskloop-with-inline-task",
@@ -488,7 +694,7 @@ var expectedTaskRunIteration1 = &v1beta1.TaskRun{
 		},
 	},
 	Spec: v1beta1.TaskRunSpec{
-		TaskRef: &v1beta1.TaskRef{Name: "a-task"},
+		TaskSpec: &commonTaskSpec,
 		Params: []v1beta1.Param{{
 			Name:  "current-item",
 			Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "item1"},
@@ -524,7 +730,7 @@ var expectedTaskRunIteration2 = &v1beta1.TaskRun{
 		},
 	},
 	Spec: v1beta1.TaskRunSpec{
-		TaskRef: &v1beta1.TaskRef{Name: "a-task"},
+		TaskSpec: &commonTaskSpec,
 		Params: []v1beta1.Param{{
 			Name:  "current-item",
 			Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "item2"},
@@ -560,7 +766,7 @@ var expectedTaskRunIteration3 = &v1beta1.TaskRun{
 		},
 	},
 	Spec: v1beta1.TaskRunSpec{
-		TaskRef: &v1beta1.TaskRef{Name: "a-task"},
+		TaskSpec: &commonTaskSpec,
 		Params: []v1beta1.Param{{
 			Name:  "current-item",
 			Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "item3"},
@@ -604,7 +810,110 @@ var expectedTaskRunWithInlineTaskIteration1 = &v1beta1.TaskRun{
 	},
 }
 
+func expectedTaskRunWithMatrix(iteration int, currentItem, additionalParameter, combination string) *v1beta1.TaskRun {
+	tr := &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("run-taskloop-with-matrix-%05d-", iteration), // does not include random suffix
+			Namespace: "foo",
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion:         "tekton.dev/v1alpha1",
+				Kind:               "Run",
+				Name:               "run-taskloop-with-matrix",
+				Controller:         &trueB,
+				BlockOwnerDeletion: &trueB,
+			}},
+			Labels: map[string]string{
+				"custom.tekton.dev/taskLoop":            "a-taskloop-with-matrix",
+				"tekton.dev/run":                        "run-taskloop-with-matrix",
+				"custom.tekton.dev/taskLoopIteration":   fmt.Sprint(iteration),
+				"custom.tekton.dev/taskLoopCombination": combination,
+			},
+			Annotations: map[string]string{},
+		},
+		Spec: v1beta1.TaskRunSpec{
+			TaskSpec: &commonTaskSpec,
+			Params: []v1beta1.Param{{
+				Name:  "current-item",
+				Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: currentItem},
+			}, {
+				Name:  "additional-parameter",
+				Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: additionalParameter},
+			}},
+			ServiceAccountName: "default",
+		},
+	}
+	// The combination label is not set when iterating in zip mode.
+	if combination == "" {
+		delete(tr.ObjectMeta.Labels, "custom.tekton.dev/taskLoopCombination")
+	}
+	return tr
+}
+
+func expectedTaskRunWithNumericRange(iteration int, currentItem string) *v1beta1.TaskRun {
+	return &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("run-taskloop-with-numeric-range-%05d-", iteration), // does not include random suffix
+			Namespace: "foo",
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion:         "tekton.dev/v1alpha1",
+				Kind:               "Run",
+				Name:               "run-taskloop-with-numeric-range",
+				Controller:         &trueB,
+				BlockOwnerDeletion: &trueB,
+			}},
+			Labels: map[string]string{
+				"custom.tekton.dev/taskLoop":          "a-taskloop-with-numeric-range",
+				"tekton.dev/run":                      "run-taskloop-with-numeric-range",
+				"custom.tekton.dev/taskLoopIteration": fmt.Sprint(iteration),
+			},
+			Annotations: map[string]string{},
+		},
+		Spec: v1beta1.TaskRunSpec{
+			TaskSpec: &commonTaskSpec,
+			Params: []v1beta1.Param{{
+				Name:  "last-item",
+				Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "7"},
+			}, {
+				Name:  "additional-parameter",
+				Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "stuff"},
+			}, {
+				Name:  "current-item",
+				Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: currentItem},
+			}},
+			ServiceAccountName: "default",
+		},
+	}
+}
+
+func expectedTaskRunWithObjectFields(iteration int, params []v1beta1.Param) *v1beta1.TaskRun {
+	return &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("run-taskloop-with-object-fields-%05d-", iteration), // does not include random suffix
+			Namespace: "foo",
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion:         "tekton.dev/v1alpha1",
+				Kind:               "Run",
+				Name:               "run-taskloop-with-object-fields",
+				Controller:         &trueB,
+				BlockOwnerDeletion: &trueB,
+			}},
+			Labels: map[string]string{
+				"custom.tekton.dev/taskLoop":          "a-taskloop-with-object-fields",
+				"tekton.dev/run":                      "run-taskloop-with-object-fields",
+				"custom.tekton.dev/taskLoopIteration": fmt.Sprint(iteration),
+			},
+			Annotations: map[string]string{},
+		},
+		Spec: v1beta1.TaskRunSpec{
+			TaskSpec:           &aTaskWithObjectFields.Spec,
+			Params:             params,
+			ServiceAccountName: "default",
+		},
+	}
+}
+
 func TestReconcileTaskLoopRun(t *testing.T) {
+	now := time.Now()
 
 	testcases := []struct {
 		name string
@@ -618,6 +927,7 @@ func TestReconcileTaskLoopRun(t *testing.T) {
 		expectedReason   taskloopv1alpha1.TaskLoopRunReason
 		expectedTaskruns []*v1beta1.TaskRun
 		expectedEvents   []string
+		expectedResults  []runv1alpha1.RunResult
 	}{{
 		name:             "Reconcile a new run with a taskloop that references a task",
 		task:             aTask,
@@ -676,7 +986,103 @@ func TestReconcileTaskLoopRun(t *testing.T) {
 		expectedStatus:   corev1.ConditionFalse,
 		expectedReason:   taskloopv1alpha1.TaskLoopRunReasonFailed,
 		expectedTaskruns: []*v1beta1.TaskRun{failed(expectedTaskRunIteration1)},
-		expectedEvents:   []string{"Warning Failed One or more TaskRuns have failed"},
+		expectedEvents:   []string{"Warning Failed One or more TaskRuns have failed. Failed iterations: 1"},
+	}, {
+		name:             "Reconcile a run that runs all iterations after the first TaskRun has failed",
+		task:             aTask,
+		taskloop:         withFailurePolicy(aTaskLoop, taskloopv1alpha1.FailurePolicyRunAll, nil),
+		run:              loopRunning(runTaskLoop),
+		taskruns:         []*v1beta1.TaskRun{failed(expectedTaskRunIteration1)},
+		expectedStatus:   corev1.ConditionUnknown,
+		expectedReason:   taskloopv1alpha1.TaskLoopRunReasonRunning,
+		expectedTaskruns: []*v1beta1.TaskRun{failed(expectedTaskRunIteration1), expectedTaskRunIteration2},
+		expectedEvents:   []string{"Normal Running Iterations completed: 1"},
+	}, {
+		name:             "Reconcile a run that runs all iterations after all TaskRuns are done and some have failed",
+		task:             aTask,
+		taskloop:         withFailurePolicy(aTaskLoop, taskloopv1alpha1.FailurePolicyRunAll, nil),
+		run:              loopRunning(runTaskLoop),
+		taskruns:         []*v1beta1.TaskRun{failed(expectedTaskRunIteration1), successful(expectedTaskRunIteration2), failed(expectedTaskRunIteration3)},
+		expectedStatus:   corev1.ConditionFalse,
+		expectedReason:   taskloopv1alpha1.TaskLoopRunReasonFailed,
+		expectedTaskruns: []*v1beta1.TaskRun{failed(expectedTaskRunIteration1), successful(expectedTaskRunIteration2), failed(expectedTaskRunIteration3)},
+		expectedEvents:   []string{"Warning Failed One or more TaskRuns have failed. Failed iterations: 1, 3"},
+	}, {
+		name:             "Reconcile a run with a failure threshold after fewer TaskRuns than the threshold have failed",
+		task:             aTask,
+		taskloop:         withFailurePolicy(aTaskLoop, taskloopv1alpha1.FailurePolicyThreshold, &failureThreshold2),
+		run:              loopRunning(runTaskLoop),
+		taskruns:         []*v1beta1.TaskRun{failed(expectedTaskRunIteration1)},
+		expectedStatus:   corev1.ConditionUnknown,
+		expectedReason:   taskloopv1alpha1.TaskLoopRunReasonRunning,
+		expectedTaskruns: []*v1beta1.TaskRun{failed(expectedTaskRunIteration1), expectedTaskRunIteration2},
+		expectedEvents:   []string{"Normal Running Iterations completed: 1"},
+	}, {
+		name:             "Reconcile a run with a failure threshold after the threshold is reached",
+		task:             aTask,
+		taskloop:         withFailurePolicy(aTaskLoop, taskloopv1alpha1.FailurePolicyThreshold, &failureThreshold2),
+		run:              loopRunning(runTaskLoop),
+		taskruns:         []*v1beta1.TaskRun{failed(expectedTaskRunIteration1), failed(expectedTaskRunIteration2)},
+		expectedStatus:   corev1.ConditionFalse,
+		expectedReason:   taskloopv1alpha1.TaskLoopRunReasonFailed,
+		expectedTaskruns: []*v1beta1.TaskRun{failed(expectedTaskRunIteration1), failed(expectedTaskRunIteration2)}, // no new TaskRun
+		expectedEvents:   []string{"Warning Failed One or more TaskRuns have failed. Failed iterations: 1, 2"},
+	}, {
+		name:             "Reconcile a run with a percentage failure threshold after all TaskRuns are done and the threshold was not reached",
+		task:             aTask,
+		taskloop:         withFailurePolicy(aTaskLoop, taskloopv1alpha1.FailurePolicyThreshold, &failureThreshold50pc),
+		run:              loopRunning(runTaskLoop),
+		taskruns:         []*v1beta1.TaskRun{successful(expectedTaskRunIteration1), failed(expectedTaskRunIteration2), successful(expectedTaskRunIteration3)},
+		expectedStatus:   corev1.ConditionTrue,
+		expectedReason:   taskloopv1alpha1.TaskLoopRunReasonSucceeded,
+		expectedTaskruns: []*v1beta1.TaskRun{successful(expectedTaskRunIteration1), failed(expectedTaskRunIteration2), successful(expectedTaskRunIteration3)},
+		expectedEvents:   []string{"Normal Succeeded TaskRuns completed with failures below the failure threshold. Failed iterations: 2"},
+	}, {
+		name:     "Reconcile a run after all TaskRuns are done and publish their results as arrays",
+		task:     aTask,
+		taskloop: withFailurePolicy(aTaskLoop, taskloopv1alpha1.FailurePolicyRunAll, nil),
+		run:      loopRunning(runTaskLoop),
+		taskruns: []*v1beta1.TaskRun{
+			withResult(successful(expectedTaskRunIteration1), "digest", "sha1"),
+			failed(expectedTaskRunIteration2),
+			withResult(successful(expectedTaskRunIteration3), "digest", "sha3"),
+		},
+		expectedStatus: corev1.ConditionFalse,
+		expectedReason: taskloopv1alpha1.TaskLoopRunReasonFailed,
+		expectedTaskruns: []*v1beta1.TaskRun{
+			withResult(successful(expectedTaskRunIteration1), "digest", "sha1"),
+			failed(expectedTaskRunIteration2),
+			withResult(successful(expectedTaskRunIteration3), "digest", "sha3"),
+		},
+		expectedEvents: []string{"Warning Failed One or more TaskRuns have failed. Failed iterations: 2"},
+		expectedResults: []runv1alpha1.RunResult{
+			{Name: "digest", Value: `["sha1",null,"sha3"]`},
+			{Name: "failed-iterations", Value: "[2]"},
+		},
+	}, {
+		name:     "Reconcile a run after all TaskRuns have succeeded and publish their results per iteration",
+		task:     aTask,
+		taskloop: withResultsFormat(aTaskLoop, taskloopv1alpha1.ResultsFormatPerIteration),
+		run:      loopRunning(runTaskLoop),
+		taskruns: []*v1beta1.TaskRun{
+			withResult(successful(expectedTaskRunIteration1), "digest", "sha1"),
+			withResult(successful(expectedTaskRunIteration2), "digest", "sha2"),
+			withResult(successful(expectedTaskRunIteration3), "digest", "sha3"),
+		},
+		expectedStatus: corev1.ConditionTrue,
+		expectedReason: taskloopv1alpha1.TaskLoopRunReasonSucceeded,
+		expectedTaskruns: []*v1beta1.TaskRun{
+			withResult(successful(expectedTaskRunIteration1), "digest", "sha1"),
+			withResult(successful(expectedTaskRunIteration2), "digest", "sha2"),
+			withResult(successful(expectedTaskRunIteration3), "digest", "sha3"),
+		},
+		expectedEvents: []string{"Normal Succeeded All TaskRuns completed successfully"},
+		expectedResults: []runv1alpha1.RunResult{
+			{Name: "digest-1", Value: "sha1"},
+			{Name: "digest-2", Value: "sha2"},
+			{Name: "digest-3", Value: "sha3"},
+			{Name: "failed-iterations", Value: "[]"},
+		},
 	}, {
 		name:             "Reconcile a run after the first TaskRun has failed and retry is allowed",
 		task:             aTask,
@@ -687,6 +1093,26 @@ func TestReconcileTaskLoopRun(t *testing.T) {
 		expectedReason:   taskloopv1alpha1.TaskLoopRunReasonRunning,
 		expectedTaskruns: []*v1beta1.TaskRun{retrying(failed(expectedTaskRunIteration1))},
 		expectedEvents:   []string{"Normal Running Iterations completed: 0"},
+	}, {
+		name:             "Reconcile a run after the first TaskRun has failed and waits for the retry backoff",
+		task:             aTask,
+		taskloop:         withRetryBackoff(allowRetry(aTaskLoop), time.Hour),
+		run:              loopRunning(runTaskLoop),
+		taskruns:         []*v1beta1.TaskRun{completedAt(failed(expectedTaskRunIteration1), now)},
+		expectedStatus:   corev1.ConditionUnknown,
+		expectedReason:   taskloopv1alpha1.TaskLoopRunReasonRunning,
+		expectedTaskruns: []*v1beta1.TaskRun{completedAt(failed(expectedTaskRunIteration1), now)},
+		expectedEvents:   []string{"Normal Running Iterations completed: 0"},
+	}, {
+		name:             "Reconcile a run after the first TaskRun has failed and the retry backoff has passed",
+		task:             aTask,
+		taskloop:         withRetryBackoff(allowRetry(aTaskLoop), time.Minute),
+		run:              loopRunning(runTaskLoop),
+		taskruns:         []*v1beta1.TaskRun{completedAt(failed(expectedTaskRunIteration1), now.Add(-time.Hour))},
+		expectedStatus:   corev1.ConditionUnknown,
+		expectedReason:   taskloopv1alpha1.TaskLoopRunReasonRunning,
+		expectedTaskruns: []*v1beta1.TaskRun{retrying(completedAt(failed(expectedTaskRunIteration1), now.Add(-time.Hour)))},
+		expectedEvents:   []string{"Normal Running Iterations completed: 0"},
 	}, {
 		name:             "Reconcile a run after the first TaskRun has failed and retry failed as well",
 		task:             aTask,
@@ -727,6 +1153,46 @@ func TestReconcileTaskLoopRun(t *testing.T) {
 		expectedReason:   v1alpha1.RunReasonCancelled,
 		expectedTaskruns: []*v1beta1.TaskRun{successful(expectedTaskRunIteration1)},
 		expectedEvents:   []string{"Warning Failed Run " + runTaskLoop.Namespace + "/" + runTaskLoop.Name + " was cancelled"},
+	}, {
+		name:             "Reconcile a run before its loop timeout has passed",
+		task:             aTask,
+		taskloop:         withLoopTimeout(aTaskLoop, time.Hour),
+		run:              loopRunning(runTaskLoop),
+		taskruns:         []*v1beta1.TaskRun{successful(expectedTaskRunIteration1)},
+		expectedStatus:   corev1.ConditionUnknown,
+		expectedReason:   taskloopv1alpha1.TaskLoopRunReasonRunning,
+		expectedTaskruns: []*v1beta1.TaskRun{successful(expectedTaskRunIteration1), expectedTaskRunIteration2},
+		expectedEvents:   []string{"Normal Running Iterations completed: 1"},
+	}, {
+		name:             "Reconcile a timed out run while the first TaskRun is running",
+		task:             aTask,
+		taskloop:         withLoopTimeout(aTaskLoop, time.Hour),
+		run:              startedAt(loopRunning(runTaskLoop), now.Add(-2*time.Hour)),
+		taskruns:         []*v1beta1.TaskRun{running(expectedTaskRunIteration1)},
+		expectedStatus:   corev1.ConditionUnknown,
+		expectedReason:   taskloopv1alpha1.TaskLoopRunReasonRunning,
+		expectedTaskruns: []*v1beta1.TaskRun{running(expectedTaskRunIteration1)},
+		expectedEvents:   []string{"Normal Running Cancelling TaskRuns after the Run timed out"},
+	}, {
+		name:             "Reconcile a timed out run after the first TaskRun has succeeded",
+		task:             aTask,
+		taskloop:         withLoopTimeout(aTaskLoop, time.Hour),
+		run:              startedAt(loopRunning(runTaskLoop), now.Add(-2*time.Hour)),
+		taskruns:         []*v1beta1.TaskRun{successful(expectedTaskRunIteration1)},
+		expectedStatus:   corev1.ConditionFalse,
+		expectedReason:   taskloopv1alpha1.TaskLoopRunReasonTimedOut,
+		expectedTaskruns: []*v1beta1.TaskRun{successful(expectedTaskRunIteration1)},
+		expectedEvents:   []string{"Warning Failed Run " + runTaskLoop.Namespace + "/" + runTaskLoop.Name + " timed out after 1h0m0s"},
+	}, {
+		name:             "Reconcile a timed out run after the first TaskRun has failed and can be retried",
+		task:             aTask,
+		taskloop:         withLoopTimeout(allowRetry(aTaskLoop), time.Hour),
+		run:              startedAt(loopRunning(runTaskLoop), now.Add(-2*time.Hour)),
+		taskruns:         []*v1beta1.TaskRun{failed(expectedTaskRunIteration1)},
+		expectedStatus:   corev1.ConditionFalse,
+		expectedReason:   taskloopv1alpha1.TaskLoopRunReasonTimedOut,
+		expectedTaskruns: []*v1beta1.TaskRun{failed(expectedTaskRunIteration1)},
+		expectedEvents:   []string{"Warning Failed Run " + runTaskLoop.Namespace + "/" + runTaskLoop.Name + " timed out after 1h0m0s"},
 	}, {
 		name:             "Reconcile a new run with a taskloop that explicitly requests sequential execution",
 		task:             aTask,
@@ -777,6 +1243,97 @@ func TestReconcileTaskLoopRun(t *testing.T) {
 		expectedReason:   taskloopv1alpha1.TaskLoopRunReasonRunning,
 		expectedTaskruns: []*v1beta1.TaskRun{failed(expectedTaskRunIteration1), running(expectedTaskRunIteration2)}, // no new TaskRun
 		expectedEvents:   []string{"Normal Running Iterations completed: 1"},
+	}, {
+		name:           "Reconcile a new run with a taskloop that iterates over a matrix of parameters",
+		task:           aTask,
+		taskloop:       withConcurrencyLimit(aTaskLoopWithMatrix, noConcurrencyLimit),
+		run:            runTaskLoopWithMatrix,
+		taskruns:       []*v1beta1.TaskRun{},
+		expectedStatus: corev1.ConditionUnknown,
+		expectedReason: taskloopv1alpha1.TaskLoopRunReasonRunning,
+		expectedTaskruns: []*v1beta1.TaskRun{
+			expectedTaskRunWithMatrix(1, "item1", "stuff", "1-1"),
+			expectedTaskRunWithMatrix(2, "item1", "things", "1-2"),
+			expectedTaskRunWithMatrix(3, "item2", "stuff", "2-1"),
+			expectedTaskRunWithMatrix(4, "item2", "things", "2-2"),
+		},
+		expectedEvents: []string{"Normal Started", "Normal Running Iterations completed: 0"},
+	}, {
+		name:     "Reconcile a run with a matrix of parameters after all TaskRuns have succeeded",
+		task:     aTask,
+		taskloop: aTaskLoopWithMatrix,
+		run:      loopRunning(runTaskLoopWithMatrix),
+		taskruns: []*v1beta1.TaskRun{
+			successful(expectedTaskRunWithMatrix(1, "item1", "stuff", "1-1")),
+			successful(expectedTaskRunWithMatrix(2, "item1", "things", "1-2")),
+			successful(expectedTaskRunWithMatrix(3, "item2", "stuff", "2-1")),
+			successful(expectedTaskRunWithMatrix(4, "item2", "things", "2-2")),
+		},
+		expectedStatus: corev1.ConditionTrue,
+		expectedReason: taskloopv1alpha1.TaskLoopRunReasonSucceeded,
+		expectedTaskruns: []*v1beta1.TaskRun{
+			successful(expectedTaskRunWithMatrix(1, "item1", "stuff", "1-1")),
+			successful(expectedTaskRunWithMatrix(2, "item1", "things", "1-2")),
+			successful(expectedTaskRunWithMatrix(3, "item2", "stuff", "2-1")),
+			successful(expectedTaskRunWithMatrix(4, "item2", "things", "2-2")),
+		},
+		expectedEvents: []string{"Normal Succeeded All TaskRuns completed successfully"},
+	}, {
+		name:           "Reconcile a new run with a taskloop that iterates over parameters in zip mode",
+		task:           aTask,
+		taskloop:       withConcurrencyLimit(withIterationMode(aTaskLoopWithMatrix, taskloopv1alpha1.IterationModeZip), noConcurrencyLimit),
+		run:            runTaskLoopWithMatrix,
+		taskruns:       []*v1beta1.TaskRun{},
+		expectedStatus: corev1.ConditionUnknown,
+		expectedReason: taskloopv1alpha1.TaskLoopRunReasonRunning,
+		expectedTaskruns: []*v1beta1.TaskRun{
+			expectedTaskRunWithMatrix(1, "item1", "stuff", ""),
+			expectedTaskRunWithMatrix(2, "item2", "things", ""),
+		},
+		expectedEvents: []string{"Normal Started", "Normal Running Iterations completed: 0"},
+	}, {
+		name:           "Reconcile a new run with a taskloop that iterates over a numeric range",
+		task:           aTask,
+		taskloop:       withConcurrencyLimit(aTaskLoopWithNumericRange, noConcurrencyLimit),
+		run:            runTaskLoopWithNumericRange,
+		taskruns:       []*v1beta1.TaskRun{},
+		expectedStatus: corev1.ConditionUnknown,
+		expectedReason: taskloopv1alpha1.TaskLoopRunReasonRunning,
+		expectedTaskruns: []*v1beta1.TaskRun{
+			expectedTaskRunWithNumericRange(1, "2"),
+			expectedTaskRunWithNumericRange(2, "4"),
+			expectedTaskRunWithNumericRange(3, "6"),
+		},
+		expectedEvents: []string{"Normal Started", "Normal Running Iterations completed: 0"},
+	}, {
+		name:           "Reconcile a new run with a taskloop that maps the fields of JSON objects onto parameters",
+		task:           aTaskWithObjectFields,
+		taskloop:       withConcurrencyLimit(aTaskLoopWithObjectFields, noConcurrencyLimit),
+		run:            runTaskLoopWithObjectFields,
+		taskruns:       []*v1beta1.TaskRun{},
+		expectedStatus: corev1.ConditionUnknown,
+		expectedReason: taskloopv1alpha1.TaskLoopRunReasonRunning,
+		expectedTaskruns: []*v1beta1.TaskRun{
+			expectedTaskRunWithObjectFields(1, []v1beta1.Param{{
+				Name:  "service",
+				Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "api"},
+			}, {
+				Name:  "cluster",
+				Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "us-west"},
+			}, {
+				Name:  "replicas",
+				Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "3"},
+			}, {
+				Name:  "ports",
+				Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeArray, ArrayVal: []string{"80", "443"}},
+			}}),
+			// Fields that are missing from the object are left to the task parameter defaults.
+			expectedTaskRunWithObjectFields(2, []v1beta1.Param{{
+				Name:  "service",
+				Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "web"},
+			}}),
+		},
+		expectedEvents: []string{"Normal Started", "Normal Running Iterations completed: 0"},
 	}, {
 		name:             "Reconcile a run where the iterate parameter is not an array",
 		task:             aTask,
@@ -861,10 +1418,22 @@ func TestReconcileTaskLoopRun(t *testing.T) {
 				}
 			}
 
+			// Verify that the Run has the expected results.
+			if tc.expectedResults != nil {
+				if d := cmp.Diff(tc.expectedResults, reconciledRun.Status.Results); d != "" {
+					t.Errorf("Run results are incorrect. Diff %s", diff.PrintWantGot(d))
+				}
+			}
+
 			// Verify Run status contains status for all TaskRuns.
 			expectedTaskRuns := map[string]taskloopv1alpha1.TaskLoopTaskRunStatus{}
 			for i, tr := range tc.expectedTaskruns {
-				expectedTaskRuns[tr.Name] = taskloopv1alpha1.TaskLoopTaskRunStatus{Iteration: i + 1, Status: &tr.Status}
+				expectedTaskRuns[tr.Name] = taskloopv1alpha1.TaskLoopTaskRunStatus{
+					Iteration:       i + 1,
+					IterationParams: getIterationParams(tr.Spec.Params, &tc.taskloop.Spec),
+					Attempts:        len(tr.Status.RetriesStatus) + 1,
+					Status:          &tr.Status,
+				}
 			}
 			checkRunStatus(t, reconciledRun, expectedTaskRuns)
 
@@ -880,6 +1449,7 @@ func TestReconcileTaskLoopRunFailures(t *testing.T) {
 	testcases := []struct {
 		name       string
 		taskloop   *taskloopv1alpha1.TaskLoop
+		tasks      []*v1beta1.Task
 		run        *v1alpha1.Run
 		reason     taskloopv1alpha1.TaskLoopRunReason
 		wantEvents []string
@@ -899,6 +1469,203 @@ func TestReconcileTaskLoopRunFailures(t *testing.T) {
 			"Normal Started ",
 			"Warning Failed Error retrieving TaskLoop",
 		},
+	}, {
+		name:     "missing one of multiple iterate parameters",
+		taskloop: aTaskLoopWithMatrix,
+		run: &v1alpha1.Run{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "bad-run-missing-matrix-param",
+				Namespace: "foo",
+			},
+			Spec: v1alpha1.RunSpec{
+				// additional-parameter, which is an iterate parameter, is missing from parameters
+				Params: []v1beta1.Param{{
+					Name:  "current-item",
+					Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeArray, ArrayVal: []string{"item1", "item2"}},
+				}},
+				Ref: &v1alpha1.TaskRef{
+					APIVersion: taskloopv1alpha1.SchemeGroupVersion.String(),
+					Kind:       taskloop.TaskLoopControllerName,
+					Name:       "a-taskloop-with-matrix",
+				},
+			},
+		},
+		reason: taskloopv1alpha1.TaskLoopRunReasonFailedValidation,
+		wantEvents: []string{
+			"Normal Started ",
+			`Warning Failed Cannot determine number of iterations: The iterate parameter "additional-parameter" was not found`,
+		},
+	}, {
+		name:     "iterate parameters with different lengths in zip mode",
+		taskloop: withIterationMode(aTaskLoopWithMatrix, taskloopv1alpha1.IterationModeZip),
+		run: &v1alpha1.Run{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "bad-run-zip-lengths",
+				Namespace: "foo",
+			},
+			Spec: v1alpha1.RunSpec{
+				Params: []v1beta1.Param{{
+					Name:  "current-item",
+					Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeArray, ArrayVal: []string{"item1", "item2", "item3"}},
+				}, {
+					Name:  "additional-parameter",
+					Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeArray, ArrayVal: []string{"stuff", "things"}},
+				}},
+				Ref: &v1alpha1.TaskRef{
+					APIVersion: taskloopv1alpha1.SchemeGroupVersion.String(),
+					Kind:       taskloop.TaskLoopControllerName,
+					Name:       "a-taskloop-with-matrix",
+				},
+			},
+		},
+		reason: taskloopv1alpha1.TaskLoopRunReasonFailedValidation,
+		wantEvents: []string{
+			"Normal Started ",
+			`Warning Failed TaskLoop foo/a-taskloop-with-matrix can't be Run; it has an invalid spec: iterate parameter "additional-parameter" has 2 values but "current-item" has 3`,
+		},
+	}, {
+		name:     "missing parameter referenced by the numeric range",
+		taskloop: aTaskLoopWithNumericRange,
+		run: &v1alpha1.Run{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "bad-run-missing-range-param",
+				Namespace: "foo",
+			},
+			Spec: v1alpha1.RunSpec{
+				// last-item, which is the end of the range, is missing from parameters
+				Params: []v1beta1.Param{{
+					Name:  "additional-parameter",
+					Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "stuff"},
+				}},
+				Ref: &v1alpha1.TaskRef{
+					APIVersion: taskloopv1alpha1.SchemeGroupVersion.String(),
+					Kind:       taskloop.TaskLoopControllerName,
+					Name:       "a-taskloop-with-numeric-range",
+				},
+			},
+		},
+		reason: taskloopv1alpha1.TaskLoopRunReasonFailedValidation,
+		wantEvents: []string{
+			"Normal Started ",
+			`Warning Failed TaskLoop foo/a-taskloop-with-numeric-range can't be Run; it has an invalid spec: invalid iterateNumeric to value: parameter "last-item" was not found`,
+		},
+	}, {
+		name:     "missing task for the iterate fields",
+		taskloop: aTaskLoopWithObjectFields,
+		run:      runTaskLoopWithObjectFields,
+		reason:   taskloopv1alpha1.TaskLoopRunReasonCouldntGetTask,
+		wantEvents: []string{
+			"Normal Started ",
+			`Warning Failed Error retrieving Task for Run foo/run-taskloop-with-object-fields: tasks.tekton.dev "a-task-with-object-fields" not found`,
+		},
+	}, {
+		name: "iterate field mapped onto an undeclared parameter",
+		taskloop: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "a-taskloop-with-object-fields", Namespace: "foo"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskSpec:      &aTaskWithObjectFields.Spec,
+				IterateParam:  "deployments",
+				IterateFields: []taskloopv1alpha1.IterateField{{Field: "service", Param: "name"}},
+			},
+		},
+		run:    runTaskLoopWithObjectFields,
+		reason: taskloopv1alpha1.TaskLoopRunReasonFailedValidation,
+		wantEvents: []string{
+			"Normal Started ",
+			"Warning Failed TaskLoop foo/a-taskloop-with-object-fields can't be Run; it has an invalid spec: invalid value: parameter name is not declared by the task: spec.iterateFields[0].param",
+		},
+	}, {
+		name:     "iterate parameter that is not a JSON array of objects",
+		taskloop: aTaskLoopWithObjectFields,
+		run: &v1alpha1.Run{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "bad-run-iterate-param-not-objects",
+				Namespace: "foo",
+			},
+			Spec: v1alpha1.RunSpec{
+				Params: []v1beta1.Param{{
+					Name:  "deployments",
+					Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeArray, ArrayVal: []string{"api", "web"}},
+				}},
+				Ref: &v1alpha1.TaskRef{
+					APIVersion: taskloopv1alpha1.SchemeGroupVersion.String(),
+					Kind:       taskloop.TaskLoopControllerName,
+					Name:       "a-taskloop-with-object-fields",
+				},
+			},
+		},
+		reason: taskloopv1alpha1.TaskLoopRunReasonFailedValidation,
+		wantEvents: []string{
+			"Normal Started ",
+			`Warning Failed TaskLoop foo/a-taskloop-with-object-fields can't be Run; it has an invalid spec: iterate parameter "deployments": item 1 is not a JSON object`,
+		},
+	}, {
+		name: "iterate field missing without a default",
+		taskloop: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "a-taskloop-with-object-fields", Namespace: "foo"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskSpec:     &aTaskWithObjectFields.Spec,
+				IterateParam: "deployments",
+				IterateFields: []taskloopv1alpha1.IterateField{
+					{Field: "service", Param: "service"},
+					{Field: "ports", Param: "ports"},
+				},
+			},
+		},
+		run: &v1alpha1.Run{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "bad-run-missing-field",
+				Namespace: "foo",
+			},
+			Spec: v1alpha1.RunSpec{
+				Params: []v1beta1.Param{{
+					Name:  "deployments",
+					Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: `[{"service":"api","ports":["80"]},{"ports":["80"]}]`},
+				}},
+				Ref: &v1alpha1.TaskRef{
+					APIVersion: taskloopv1alpha1.SchemeGroupVersion.String(),
+					Kind:       taskloop.TaskLoopControllerName,
+					Name:       "a-taskloop-with-object-fields",
+				},
+			},
+		},
+		reason: taskloopv1alpha1.TaskLoopRunReasonFailedValidation,
+		wantEvents: []string{
+			"Normal Started ",
+			`Warning Failed Cannot map the fields of iterate parameter "deployments": item 2 has no field "service"`,
+		},
+	}, {
+		name:     "task that declares a reserved result",
+		taskloop: aTaskLoop,
+		tasks: []*v1beta1.Task{{
+			ObjectMeta: metav1.ObjectMeta{Name: "a-task", Namespace: "foo"},
+			Spec: v1beta1.TaskSpec{
+				Params:  commonTaskSpec.Params,
+				Steps:   commonTaskSpec.Steps,
+				Results: []v1beta1.TaskResult{{Name: "failed-iterations"}},
+			},
+		}},
+		run:    runTaskLoop,
+		reason: taskloopv1alpha1.TaskLoopRunReasonFailedValidation,
+		wantEvents: []string{
+			"Normal Started ",
+			`Warning Failed TaskLoop foo/a-taskloop can't be Run; its task has an invalid spec: result name "failed-iterations" is reserved by TaskLoop: results[0].name`,
+		},
+	}, {
+		name: "bundle without the service account of the Run",
+		taskloop: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "a-taskloop", Namespace: "foo"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef:      &v1beta1.TaskRef{Name: "a-bundled-task", Bundle: "registry.example.com/taskloop/bundle:latest"},
+				IterateParam: "current-item",
+			},
+		},
+		run:    runTaskLoop,
+		reason: taskloopv1alpha1.TaskLoopRunReasonCouldntGetTask,
+		wantEvents: []string{
+			"Normal Started ",
+			`Warning Failed Error retrieving Task for Run foo/run-taskloop: failed to get keychain: serviceaccounts "default" not found`,
+		},
 	}, {
 		name:     "missing iterate parameter",
 		taskloop: aTaskLoop,
@@ -915,7 +1682,8 @@ func TestReconcileTaskLoopRunFailures(t *testing.T) {
 			ctx := context.Background()
 
 			d := test.Data{
-				Runs: []*v1alpha1.Run{tc.run},
+				Runs:  []*v1alpha1.Run{tc.run},
+				Tasks: tc.tasks,
 			}
 
 			optionalTaskLoop := []*taskloopv1alpha1.TaskLoop{tc.taskloop}
@@ -952,3 +1720,333 @@ func TestReconcileTaskLoopRunFailures(t *testing.T) {
 		})
 	}
 }
+
+func TestReconcileTaskLoopRunResolvesTask(t *testing.T) {
+	aClusterTask := &v1beta1.ClusterTask{
+		ObjectMeta: metav1.ObjectMeta{Name: "a-cluster-task"},
+		Spec:       commonTaskSpec,
+	}
+	testcases := []struct {
+		name     string
+		taskloop *taskloopv1alpha1.TaskLoop
+	}{{
+		name:     "task",
+		taskloop: aTaskLoop,
+	}, {
+		name: "cluster task",
+		taskloop: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "a-taskloop", Namespace: "foo"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskRef:      &v1beta1.TaskRef{Name: "a-cluster-task", Kind: v1beta1.ClusterTaskKind},
+				IterateParam: "current-item",
+			},
+		},
+	}, {
+		name: "inline task",
+		taskloop: &taskloopv1alpha1.TaskLoop{
+			ObjectMeta: metav1.ObjectMeta{Name: "a-taskloop", Namespace: "foo"},
+			Spec: taskloopv1alpha1.TaskLoopSpec{
+				TaskSpec:     &commonTaskSpec,
+				IterateParam: "current-item",
+			},
+		},
+	}}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			names.TestingSeed()
+
+			d := test.Data{
+				Runs:         []*v1alpha1.Run{runTaskLoop},
+				Tasks:        []*v1beta1.Task{aTask},
+				ClusterTasks: []*v1beta1.ClusterTask{aClusterTask},
+			}
+			testAssets, _ := getTaskLoopController(t, d, []*taskloopv1alpha1.TaskLoop{tc.taskloop})
+			clients := testAssets.Clients
+
+			if err := testAssets.Controller.Reconciler.Reconcile(ctx, getRunName(runTaskLoop)); err != nil {
+				t.Fatalf("Error reconciling: %s", err)
+			}
+			reconciledRun, err := clients.Pipeline.TektonV1alpha1().Runs(runTaskLoop.Namespace).Get(ctx, runTaskLoop.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Error getting reconciled run from fake client: %s", err)
+			}
+			checkRunCondition(t, reconciledRun, corev1.ConditionUnknown, taskloopv1alpha1.TaskLoopRunReasonRunning)
+
+			// Verify that the Run status contains the spec of the task.
+			status := &taskloopv1alpha1.TaskLoopRunStatus{}
+			if err := reconciledRun.Status.DecodeExtraFields(status); err != nil {
+				t.Fatalf("DecodeExtraFields error: %v", err.Error())
+			}
+			if d := cmp.Diff(&commonTaskSpec, status.TaskSpec); d != "" {
+				t.Errorf("Run status has incorrect task spec. Diff %s", diff.PrintWantGot(d))
+			}
+
+			// Verify that the TaskRun embeds the spec of the task rather than referencing it.
+			createdTaskRuns := getCreatedTaskRuns(t, clients)
+			if len(createdTaskRuns) != 1 {
+				t.Fatalf("Expected 1 TaskRun to be created but found %d", len(createdTaskRuns))
+			}
+			if createdTaskRuns[0].Spec.TaskRef != nil {
+				t.Errorf("Expected TaskRun to have no taskRef but it has %v", createdTaskRuns[0].Spec.TaskRef)
+			}
+			if d := cmp.Diff(&commonTaskSpec, createdTaskRuns[0].Spec.TaskSpec); d != "" {
+				t.Errorf("TaskRun has incorrect task spec. Diff %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestReconcileTaskLoopRunWithBundle(t *testing.T) {
+	ctx := context.Background()
+	names.TestingSeed()
+
+	// Set up a fake registry to push the bundle to.
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle := u.Host + "/taskloop/bundle:latest"
+	pushTask := func(image string) {
+		task := &v1beta1.Task{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "tekton.dev/v1beta1", Kind: "Task"},
+			ObjectMeta: metav1.ObjectMeta{Name: "a-bundled-task"},
+			Spec: v1beta1.TaskSpec{
+				Params: []v1beta1.ParamSpec{{Name: "current-item", Type: v1beta1.ParamTypeString}},
+				Steps: []v1beta1.Step{{
+					Container: corev1.Container{Name: "foo", Image: image},
+				}},
+			},
+		}
+		if _, err := ptest.CreateImage(bundle, task); err != nil {
+			t.Fatalf("Error pushing bundle: %s", err)
+		}
+	}
+	pushTask("bar")
+
+	taskLoop := &taskloopv1alpha1.TaskLoop{
+		ObjectMeta: metav1.ObjectMeta{Name: "a-taskloop", Namespace: "foo"},
+		Spec: taskloopv1alpha1.TaskLoopSpec{
+			TaskRef:      &v1beta1.TaskRef{Name: "a-bundled-task", Bundle: bundle},
+			IterateParam: "current-item",
+		},
+	}
+	d := test.Data{
+		Runs: []*v1alpha1.Run{runTaskLoop},
+		// The bundle is pulled with the credentials of the Run's service account.
+		ServiceAccounts: []*corev1.ServiceAccount{{
+			ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "foo"},
+		}},
+	}
+	testAssets, _ := getTaskLoopController(t, d, []*taskloopv1alpha1.TaskLoop{taskLoop})
+	clients := testAssets.Clients
+
+	// The first reconcile resolves the bundle and creates the first TaskRun.
+	if err := testAssets.Controller.Reconciler.Reconcile(ctx, getRunName(runTaskLoop)); err != nil {
+		t.Fatalf("Error reconciling: %s", err)
+	}
+	createdTaskRuns := getCreatedTaskRuns(t, clients)
+	if len(createdTaskRuns) != 1 {
+		t.Fatalf("Expected 1 TaskRun to be created but found %d", len(createdTaskRuns))
+	}
+	firstTaskRun := createdTaskRuns[0]
+	if firstTaskRun.Spec.TaskRef != nil || firstTaskRun.Spec.TaskSpec == nil {
+		t.Fatalf("Expected TaskRun to embed the task from the bundle but it has taskRef %v", firstTaskRun.Spec.TaskRef)
+	}
+	if image := firstTaskRun.Spec.TaskSpec.Steps[0].Image; image != "bar" {
+		t.Errorf("Expected TaskRun to run the task from the bundle but its step has image %s", image)
+	}
+
+	// Move the bundle tag to a different task and let the first TaskRun complete.
+	pushTask("changed")
+	tr, err := clients.Pipeline.TektonV1beta1().TaskRuns(firstTaskRun.Namespace).Get(ctx, firstTaskRun.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Error getting TaskRun: %s", err)
+	}
+	if _, err := clients.Pipeline.TektonV1beta1().TaskRuns(tr.Namespace).UpdateStatus(ctx, successful(tr), metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Error updating TaskRun status: %s", err)
+	}
+
+	// The second reconcile creates the next TaskRun from the task that was resolved for the Run.
+	if err := testAssets.Controller.Reconciler.Reconcile(ctx, getRunName(runTaskLoop)); err != nil {
+		t.Fatalf("Error reconciling: %s", err)
+	}
+	createdTaskRuns = getCreatedTaskRuns(t, clients)
+	if len(createdTaskRuns) != 2 {
+		t.Fatalf("Expected 2 TaskRuns to be created but found %d", len(createdTaskRuns))
+	}
+	for _, tr := range createdTaskRuns {
+		if d := cmp.Diff(firstTaskRun.Spec.TaskSpec, tr.Spec.TaskSpec); d != "" {
+			t.Errorf("TaskRun %s does not run the task that was resolved for the Run. Diff %s", tr.Name, diff.PrintWantGot(d))
+		}
+	}
+}
+
+func TestReconcileTaskLoopRunWithNamespaceConcurrency(t *testing.T) {
+	limit1, limit2 := 1, 2
+	otherTaskRun := func(labelValue string) *v1beta1.TaskRun {
+		return &v1beta1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "other-run-00001-abcde",
+				Namespace: "foo",
+				Labels: map[string]string{
+					"custom.tekton.dev/taskLoop": "other-taskloop",
+					"tekton.dev/run":             "other-run",
+					"myRunLabel":                 labelValue,
+				},
+			},
+			Spec: v1beta1.TaskRunSpec{TaskRef: &v1beta1.TaskRef{Name: "a-task"}},
+		}
+	}
+	limitConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "taskloop-limits", Namespace: "foo"},
+		Data:       map[string]string{"limit": "2"},
+	}
+
+	testcases := []struct {
+		name                string
+		nc                  *taskloopv1alpha1.NamespaceConcurrency
+		taskruns            []*v1beta1.TaskRun
+		configMaps          []*corev1.ConfigMap
+		expectedStatus      corev1.ConditionStatus
+		expectedReason      taskloopv1alpha1.TaskLoopRunReason
+		expectedNewTaskRuns int
+	}{{
+		name:                "limit shared with a running TaskRun of another Run",
+		nc:                  &taskloopv1alpha1.NamespaceConcurrency{Limit: &limit2},
+		taskruns:            []*v1beta1.TaskRun{running(otherTaskRun("myRunLabelValue"))},
+		expectedStatus:      corev1.ConditionUnknown,
+		expectedReason:      taskloopv1alpha1.TaskLoopRunReasonRunning,
+		expectedNewTaskRuns: 1,
+	}, {
+		name:                "limit shared with a completed TaskRun of another Run",
+		nc:                  &taskloopv1alpha1.NamespaceConcurrency{Limit: &limit2},
+		taskruns:            []*v1beta1.TaskRun{successful(otherTaskRun("myRunLabelValue"))},
+		expectedStatus:      corev1.ConditionUnknown,
+		expectedReason:      taskloopv1alpha1.TaskLoopRunReasonRunning,
+		expectedNewTaskRuns: 2,
+	}, {
+		name:                "limit reached by the TaskRuns of other Runs",
+		nc:                  &taskloopv1alpha1.NamespaceConcurrency{Limit: &limit1},
+		taskruns:            []*v1beta1.TaskRun{running(otherTaskRun("myRunLabelValue"))},
+		expectedStatus:      corev1.ConditionUnknown,
+		expectedReason:      taskloopv1alpha1.TaskLoopRunReasonRunning,
+		expectedNewTaskRuns: 0,
+	}, {
+		name:                "limit keyed by a label with the same value",
+		nc:                  &taskloopv1alpha1.NamespaceConcurrency{LabelKey: "myRunLabel", Limit: &limit2},
+		taskruns:            []*v1beta1.TaskRun{running(otherTaskRun("myRunLabelValue"))},
+		expectedStatus:      corev1.ConditionUnknown,
+		expectedReason:      taskloopv1alpha1.TaskLoopRunReasonRunning,
+		expectedNewTaskRuns: 1,
+	}, {
+		name:                "limit keyed by a label with a different value",
+		nc:                  &taskloopv1alpha1.NamespaceConcurrency{LabelKey: "myRunLabel", Limit: &limit2},
+		taskruns:            []*v1beta1.TaskRun{running(otherTaskRun("otherValue"))},
+		expectedStatus:      corev1.ConditionUnknown,
+		expectedReason:      taskloopv1alpha1.TaskLoopRunReasonRunning,
+		expectedNewTaskRuns: 2,
+	}, {
+		name:                "limit keyed by a label that the Run doesn't have",
+		nc:                  &taskloopv1alpha1.NamespaceConcurrency{LabelKey: "team", Limit: &limit1},
+		taskruns:            []*v1beta1.TaskRun{running(otherTaskRun("myRunLabelValue"))},
+		expectedStatus:      corev1.ConditionUnknown,
+		expectedReason:      taskloopv1alpha1.TaskLoopRunReasonRunning,
+		expectedNewTaskRuns: 3,
+	}, {
+		name: "limit read from a ConfigMap",
+		nc: &taskloopv1alpha1.NamespaceConcurrency{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "taskloop-limits"},
+			Key:                  "limit",
+		}},
+		taskruns:            []*v1beta1.TaskRun{running(otherTaskRun("myRunLabelValue"))},
+		configMaps:          []*corev1.ConfigMap{limitConfigMap},
+		expectedStatus:      corev1.ConditionUnknown,
+		expectedReason:      taskloopv1alpha1.TaskLoopRunReasonRunning,
+		expectedNewTaskRuns: 1,
+	}, {
+		name: "limit read from a ConfigMap without the key",
+		nc: &taskloopv1alpha1.NamespaceConcurrency{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "taskloop-limits"},
+			Key:                  "missing",
+		}},
+		configMaps:          []*corev1.ConfigMap{limitConfigMap},
+		expectedStatus:      corev1.ConditionFalse,
+		expectedReason:      taskloopv1alpha1.TaskLoopRunReasonCouldntGetConcurrencyLimit,
+		expectedNewTaskRuns: 0,
+	}, {
+		name: "limit read from a nonexistent ConfigMap",
+		nc: &taskloopv1alpha1.NamespaceConcurrency{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "nonexistent"},
+			Key:                  "limit",
+		}},
+		expectedStatus:      corev1.ConditionFalse,
+		expectedReason:      taskloopv1alpha1.TaskLoopRunReasonCouldntGetConcurrencyLimit,
+		expectedNewTaskRuns: 0,
+	}}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			names.TestingSeed()
+
+			taskloop := withNamespaceConcurrency(withConcurrencyLimit(aTaskLoop, noConcurrencyLimit), tc.nc)
+			d := test.Data{
+				Runs:       []*v1alpha1.Run{runTaskLoop},
+				Tasks:      []*v1beta1.Task{aTask},
+				TaskRuns:   tc.taskruns,
+				ConfigMaps: tc.configMaps,
+			}
+			testAssets, _ := getTaskLoopController(t, d, []*taskloopv1alpha1.TaskLoop{taskloop})
+			clients := testAssets.Clients
+
+			if err := testAssets.Controller.Reconciler.Reconcile(ctx, getRunName(runTaskLoop)); err != nil {
+				t.Fatalf("Error reconciling: %s", err)
+			}
+			reconciledRun, err := clients.Pipeline.TektonV1alpha1().Runs(runTaskLoop.Namespace).Get(ctx, runTaskLoop.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Error getting reconciled run from fake client: %s", err)
+			}
+			checkRunCondition(t, reconciledRun, tc.expectedStatus, tc.expectedReason)
+
+			if createdTaskRuns := getCreatedTaskRuns(t, clients); len(createdTaskRuns) != tc.expectedNewTaskRuns {
+				t.Errorf("Expected %d TaskRuns to be created but found %d", tc.expectedNewTaskRuns, len(createdTaskRuns))
+			}
+		})
+	}
+}
+
+func TestReconcileTaskLoopRunCancelsTaskRunsAfterLoopTimeout(t *testing.T) {
+	ctx := context.Background()
+	names.TestingSeed()
+
+	run := startedAt(loopRunning(runTaskLoop), time.Now().Add(-2*time.Hour))
+	d := test.Data{
+		Runs:     []*v1alpha1.Run{run},
+		Tasks:    []*v1beta1.Task{aTask},
+		TaskRuns: []*v1beta1.TaskRun{running(expectedTaskRunIteration1)},
+	}
+	testAssets, _ := getTaskLoopController(t, d, []*taskloopv1alpha1.TaskLoop{withLoopTimeout(aTaskLoop, time.Hour)})
+	clients := testAssets.Clients
+
+	if err := testAssets.Controller.Reconciler.Reconcile(ctx, getRunName(run)); err != nil {
+		t.Fatalf("Error reconciling: %s", err)
+	}
+
+	// Verify that the running TaskRun was patched with the cancellation.
+	patched := false
+	for _, a := range clients.Pipeline.Actions() {
+		if a.GetVerb() == "patch" && a.GetResource().Resource == "taskruns" {
+			if name := a.(ktesting.PatchAction).GetName(); name != expectedTaskRunIteration1.Name {
+				t.Errorf("Expected TaskRun %s to be cancelled but %s was patched", expectedTaskRunIteration1.Name, name)
+			}
+			patched = true
+		}
+	}
+	if !patched {
+		t.Errorf("Expected TaskRun %s to be cancelled", expectedTaskRunIteration1.Name)
+	}
+}